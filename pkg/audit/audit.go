@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// PathStdout writes the audit log records to the standard output
+	PathStdout = "-"
+
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+
+	ActionApply  = "apply"
+	ActionDelete = "delete"
+)
+
+type Options struct {
+	// Path of the audit log file, PathStdout for the standard output, the audit log is disabled if empty
+	Path string
+	// MaxSize is the maximum size in megabytes of the audit log file before it gets rotated
+	MaxSize int
+	// MaxBackups is the maximum number of rotated audit log files to retain (all of them if 0)
+	MaxBackups int
+}
+
+// Record is the audit log entry of a tool call, serialized as a JSON line
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	SessionID string    `json:"sessionId,omitempty"`
+	User      string    `json:"user,omitempty"`
+	Groups    []string  `json:"groups,omitempty"`
+	Tool      string    `json:"tool"`
+	// Arguments of the tool call with the sensitive values redacted
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// Cluster is the URL of the target Kubernetes API server
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Outcome   string `json:"outcome"`
+	// Reason of the failure (Kubernetes status reason or server policy)
+	Reason   string  `json:"reason,omitempty"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"durationSeconds"`
+	// Objects created, updated, or deleted by the tool call
+	Objects []Object `json:"objects,omitempty"`
+
+	mu sync.Mutex
+}
+
+// Object identifies an object modified by a tool call
+type Object struct {
+	Action          string `json:"action"`
+	APIVersion      string `json:"apiVersion"`
+	Kind            string `json:"kind"`
+	Namespace       string `json:"namespace,omitempty"`
+	Name            string `json:"name"`
+	UID             string `json:"uid,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+var (
+	mu     sync.Mutex
+	writer io.Writer
+)
+
+// Init enables the audit log with the provided options, the returned function closes the audit log file
+func Init(options Options) (func() error, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	switch options.Path {
+	case "":
+		writer = nil
+		return func() error { return nil }, nil
+	case PathStdout:
+		writer = os.Stdout
+		return func() error { return nil }, nil
+	}
+	if options.MaxSize < 0 || options.MaxBackups < 0 {
+		return nil, errors.New("audit log max size and max backups must not be negative")
+	}
+	file, err := os.OpenFile(options.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	_ = file.Close()
+	rotatingWriter := &lumberjack.Logger{
+		Filename:   options.Path,
+		MaxSize:    options.MaxSize,
+		MaxBackups: options.MaxBackups,
+	}
+	writer = rotatingWriter
+	return func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if writer == rotatingWriter {
+			writer = nil
+		}
+		return rotatingWriter.Close()
+	}, nil
+}
+
+// Enabled returns true if the audit log is enabled
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return writer != nil
+}
+
+// Log writes the provided record to the audit log
+func Log(record *Record) error {
+	record.mu.Lock()
+	line, err := json.Marshal(record)
+	record.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if writer == nil {
+		return nil
+	}
+	_, err = writer.Write(append(line, '\n'))
+	return err
+}
+
+type recordKey struct{}
+
+// WithRecord returns a copy of the context that carries the audit log record of the current tool call
+func WithRecord(ctx context.Context, record *Record) context.Context {
+	return context.WithValue(ctx, recordKey{}, record)
+}
+
+// RecordFrom returns the audit log record carried by the context, or nil if there is none
+func RecordFrom(ctx context.Context) *Record {
+	record, _ := ctx.Value(recordKey{}).(*Record)
+	return record
+}
+
+// SetCluster sets the target cluster of the audit log record carried by the context (if any)
+func SetCluster(ctx context.Context, cluster string) {
+	if record := RecordFrom(ctx); record != nil {
+		record.mu.Lock()
+		defer record.mu.Unlock()
+		record.Cluster = cluster
+	}
+}
+
+// AddObject adds the identity of an object modified by the tool call to the audit log record carried by the context (if any)
+func AddObject(ctx context.Context, action string, gvk schema.GroupVersionKind, obj metav1.Object) {
+	if record := RecordFrom(ctx); record != nil {
+		record.mu.Lock()
+		defer record.mu.Unlock()
+		record.Objects = append(record.Objects, Object{
+			Action:          action,
+			APIVersion:      gvk.GroupVersion().String(),
+			Kind:            gvk.Kind,
+			Namespace:       obj.GetNamespace(),
+			Name:            obj.GetName(),
+			UID:             string(obj.GetUID()),
+			ResourceVersion: obj.GetResourceVersion(),
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readRecords(t *testing.T, path string) []map[string]any {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	var records []map[string]any
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 4*1024*1024)
+	for scanner.Scan() {
+		record := map[string]any{}
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid audit log line %s: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestDisabled(t *testing.T) {
+	closeAuditLog, err := Init(Options{})
+	if err != nil {
+		t.Fatalf("failed to initialize audit log %v", err)
+	}
+	defer func() { _ = closeAuditLog() }()
+	t.Run("Audit log is disabled without path", func(t *testing.T) {
+		if Enabled() {
+			t.Fatal("expected audit log to be disabled")
+		}
+	})
+	t.Run("Log without audit log is a no-op", func(t *testing.T) {
+		if err = Log(&Record{Tool: "pods_list"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	closeAuditLog, err := Init(Options{Path: path})
+	if err != nil {
+		t.Fatalf("failed to initialize audit log %v", err)
+	}
+	record := &Record{Tool: "resources_create_or_update", Outcome: OutcomeSuccess, Arguments: map[string]any{"namespace": "default"}}
+	ctx := WithRecord(context.Background(), record)
+	SetCluster(ctx, "https://kubernetes.example.com")
+	AddObject(ctx, ActionApply, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, &metav1.ObjectMeta{
+		Namespace: "default", Name: "a-deployment", UID: "a-uid", ResourceVersion: "1337",
+	})
+	err = Log(record)
+	_ = closeAuditLog()
+	t.Run("Audit log is disabled after close", func(t *testing.T) {
+		if Enabled() {
+			t.Fatal("expected audit log to be disabled after close")
+		}
+	})
+	t.Run("Log writes record", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("failed to write record %v", err)
+		}
+		records := readRecords(t, path)
+		if len(records) != 1 {
+			t.Fatalf("expected 1 record, got %d", len(records))
+		}
+		if records[0]["tool"] != "resources_create_or_update" || records[0]["outcome"] != "success" {
+			t.Fatalf("invalid record, got %v", records[0])
+		}
+		if records[0]["cluster"] != "https://kubernetes.example.com" {
+			t.Fatalf("invalid cluster, got %v", records[0]["cluster"])
+		}
+	})
+	t.Run("Log writes modified objects", func(t *testing.T) {
+		objects := readRecords(t, path)[0]["objects"].([]any)
+		if len(objects) != 1 {
+			t.Fatalf("expected 1 object, got %v", objects)
+		}
+		object := objects[0].(map[string]any)
+		if object["action"] != "apply" || object["apiVersion"] != "apps/v1" || object["kind"] != "Deployment" ||
+			object["uid"] != "a-uid" || object["resourceVersion"] != "1337" {
+			t.Fatalf("invalid object, got %v", object)
+		}
+	})
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	closeAuditLog, err := Init(Options{Path: path, MaxSize: 1})
+	if err != nil {
+		t.Fatalf("failed to initialize audit log %v", err)
+	}
+	defer func() { _ = closeAuditLog() }()
+	large := strings.Repeat("a", 300*1024)
+	for i := 0; i < 10; i++ {
+		if err = Log(&Record{Tool: "pods_exec", Arguments: map[string]any{"command": large}}); err != nil {
+			t.Fatalf("failed to write record %v", err)
+		}
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "audit*.log"))
+	t.Run("Audit log is rotated when it exceeds the max size", func(t *testing.T) {
+		if len(files) < 2 {
+			t.Fatalf("expected current and backup files, got %v", files)
+		}
+	})
+}
+
+func TestContextWithoutRecord(t *testing.T) {
+	t.Run("AddObject and SetCluster without record are no-op", func(t *testing.T) {
+		SetCluster(context.Background(), "https://kubernetes.example.com")
+		AddObject(context.Background(), ActionDelete, schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, &metav1.ObjectMeta{Name: "a-pod"})
+		if RecordFrom(context.Background()) != nil {
+			t.Fatal("expected no record")
+		}
+	})
+}
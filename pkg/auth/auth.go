@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthorized is returned when the provided bearer token can't be authenticated
+var ErrUnauthorized = errors.New("unauthorized")
+
+// User is the identity resolved from an authenticated bearer token
+type User struct {
+	// Name is the user name (token file user or JWT subject)
+	Name string
+	// Groups are the groups the user belongs to
+	Groups []string
+	// Token is the raw bearer token provided in the request
+	Token string
+	// Passthrough is true if the Token can be forwarded to the Kubernetes API server (JWTs issued by the OIDC provider).
+	// The static tokens of the token file are the server's own secrets and are never forwarded.
+	Passthrough bool
+}
+
+// Authenticator validates bearer tokens and resolves the identity of their owner
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*User, error)
+}
+
+// Authenticators tries each of the Authenticators in order and returns the first successfully authenticated User
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(ctx context.Context, token string) (*User, error) {
+	for _, authenticator := range a {
+		if user, err := authenticator.Authenticate(ctx, token); err == nil {
+			return user, nil
+		}
+	}
+	return nil, ErrUnauthorized
+}
+
+type contextKey struct{}
+
+// WithUser returns a copy of the provided context with the authenticated User
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFrom returns the authenticated User in the provided context (nil if unauthenticated)
+func UserFrom(ctx context.Context) *User {
+	user, _ := ctx.Value(contextKey{}).(*User)
+	return user
+}
+
+// BearerToken extracts the bearer token from the Authorization header of the request
+func BearerToken(r *http.Request) string {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBearerToken(t *testing.T) {
+	for header, expected := range map[string]string{
+		"Bearer a-token":   "a-token",
+		"bearer a-token":   "a-token",
+		"Basic dXNlcjpwdw": "",
+		"a-token":          "",
+		"":                 "",
+	} {
+		t.Run("BearerToken for '"+header+"' returns '"+expected+"'", func(t *testing.T) {
+			r, _ := http.NewRequest("GET", "/", nil)
+			r.Header.Set("Authorization", header)
+			if token := BearerToken(r); token != expected {
+				t.Fatalf("invalid token, expected %s, got %s", expected, token)
+			}
+		})
+	}
+}
+
+func TestTokenFileAuthenticator(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "tokens.csv")
+	_ = os.WriteFile(tokenFile, []byte(`# token,user,uid,groups
+token-alice,alice,1,"team-a,developers"
+token-bob,bob
+`), 0600)
+	authenticator, err := NewTokenFileAuthenticator(tokenFile)
+	if err != nil {
+		t.Fatalf("failed to create authenticator %v", err)
+	}
+	t.Run("Authenticate valid token returns user", func(t *testing.T) {
+		user, err := authenticator.Authenticate(context.Background(), "token-alice")
+		if err != nil {
+			t.Fatalf("failed to authenticate %v", err)
+		}
+		if user.Name != "alice" {
+			t.Errorf("invalid user name, expected alice, got %s", user.Name)
+		}
+		if !slices.Equal(user.Groups, []string{"team-a", "developers"}) {
+			t.Errorf("invalid user groups, got %v", user.Groups)
+		}
+		if user.Token != "token-alice" {
+			t.Errorf("invalid user token, got %s", user.Token)
+		}
+		if user.Passthrough {
+			t.Errorf("expected static token not to be passed through")
+		}
+	})
+	t.Run("Authenticate valid token without groups returns user", func(t *testing.T) {
+		user, err := authenticator.Authenticate(context.Background(), "token-bob")
+		if err != nil {
+			t.Fatalf("failed to authenticate %v", err)
+		}
+		if user.Name != "bob" || len(user.Groups) != 0 {
+			t.Errorf("invalid user, got %v", user)
+		}
+	})
+	t.Run("Authenticate invalid token returns ErrUnauthorized", func(t *testing.T) {
+		if _, err := authenticator.Authenticate(context.Background(), "token-eve"); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+	t.Run("NewTokenFileAuthenticator with invalid file returns error", func(t *testing.T) {
+		invalidTokenFile := filepath.Join(t.TempDir(), "invalid.csv")
+		_ = os.WriteFile(invalidTokenFile, []byte("token-without-user\n"), 0600)
+		if _, err := NewTokenFileAuthenticator(invalidTokenFile); err == nil {
+			t.Fatalf("expected error for invalid token file")
+		}
+	})
+	t.Run("NewTokenFileAuthenticator with missing file returns error", func(t *testing.T) {
+		if _, err := NewTokenFileAuthenticator(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+			t.Fatalf("expected error for missing token file")
+		}
+	})
+}
+
+func TestOIDCAuthenticatorWithJwksFile(t *testing.T) {
+	const issuer = "https://issuer.example.com"
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	jwks, _ := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &key.PublicKey, KeyID: "test-key", Algorithm: string(jose.RS256), Use: "sig"},
+	}})
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	_ = os.WriteFile(jwksFile, jwks, 0600)
+	signer, _ := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test-key"))
+	sign := func(claims jwt.Claims, groups []string) string {
+		token, _ := jwt.Signed(signer).Claims(claims).Claims(map[string]any{"groups": groups}).Serialize()
+		return token
+	}
+	validClaims := jwt.Claims{
+		Issuer:   issuer,
+		Subject:  "alice",
+		Audience: jwt.Audience{"kubernetes-mcp-server"},
+		Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		IssuedAt: jwt.NewNumericDate(time.Now()),
+	}
+	authenticator, err := NewOIDCAuthenticator(context.Background(), OIDCOptions{IssuerURL: issuer, Audience: "kubernetes-mcp-server", JWKSFile: jwksFile})
+	if err != nil {
+		t.Fatalf("failed to create authenticator %v", err)
+	}
+	t.Run("Authenticate valid JWT returns user with issuer prefixed name and groups", func(t *testing.T) {
+		user, err := authenticator.Authenticate(context.Background(), sign(validClaims, []string{"team-a", "system:masters"}))
+		if err != nil {
+			t.Fatalf("failed to authenticate %v", err)
+		}
+		if user.Name != issuer+"#alice" {
+			t.Errorf("invalid user name, expected %s#alice, got %s", issuer, user.Name)
+		}
+		if !slices.Equal(user.Groups, []string{issuer + "#team-a", issuer + "#system:masters"}) {
+			t.Errorf("invalid user groups, got %v", user.Groups)
+		}
+		if !user.Passthrough {
+			t.Errorf("expected OIDC user token to be passed through")
+		}
+	})
+	t.Run("Authenticate expired JWT returns ErrUnauthorized", func(t *testing.T) {
+		claims := validClaims
+		claims.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		if _, err := authenticator.Authenticate(context.Background(), sign(claims, nil)); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+	t.Run("Authenticate JWT with invalid audience returns ErrUnauthorized", func(t *testing.T) {
+		claims := validClaims
+		claims.Audience = jwt.Audience{"another-audience"}
+		if _, err := authenticator.Authenticate(context.Background(), sign(claims, nil)); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+	t.Run("Authenticate JWT with invalid issuer returns ErrUnauthorized", func(t *testing.T) {
+		claims := validClaims
+		claims.Issuer = "https://another-issuer.example.com"
+		if _, err := authenticator.Authenticate(context.Background(), sign(claims, nil)); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+	t.Run("Authenticate JWT signed with another key returns ErrUnauthorized", func(t *testing.T) {
+		anotherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		anotherSigner, _ := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: anotherKey}, nil)
+		token, _ := jwt.Signed(anotherSigner).Claims(validClaims).Serialize()
+		if _, err := authenticator.Authenticate(context.Background(), token); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+	t.Run("Authenticate non JWT token returns ErrUnauthorized", func(t *testing.T) {
+		if _, err := authenticator.Authenticate(context.Background(), "not-a-jwt"); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+}
+
+func TestOIDCAuthenticatorPrefixes(t *testing.T) {
+	issuer := "https://issuer.example.com"
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	jwks, _ := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &key.PublicKey, KeyID: "test-key", Algorithm: string(jose.RS256), Use: "sig"},
+	}})
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	_ = os.WriteFile(jwksFile, jwks, 0600)
+	signer, _ := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test-key"))
+	sign := func(subject string, groups []string) string {
+		token, _ := jwt.Signed(signer).Claims(jwt.Claims{
+			Issuer:   issuer,
+			Subject:  subject,
+			Audience: jwt.Audience{"kubernetes-mcp-server"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}).Claims(map[string]any{"groups": groups}).Serialize()
+		return token
+	}
+	t.Run("Authenticate with custom prefixes returns user with prefixed name and groups", func(t *testing.T) {
+		authenticator, _ := NewOIDCAuthenticator(context.Background(), OIDCOptions{
+			IssuerURL: issuer, Audience: "kubernetes-mcp-server", JWKSFile: jwksFile, UsernamePrefix: "oidc:", GroupsPrefix: "oidc:",
+		})
+		user, err := authenticator.Authenticate(context.Background(), sign("alice", []string{"team-a"}))
+		if err != nil {
+			t.Fatalf("failed to authenticate %v", err)
+		}
+		if user.Name != "oidc:alice" || !slices.Equal(user.Groups, []string{"oidc:team-a"}) {
+			t.Errorf("invalid user, got %s %v", user.Name, user.Groups)
+		}
+	})
+	disabled, _ := NewOIDCAuthenticator(context.Background(), OIDCOptions{
+		IssuerURL: issuer, Audience: "kubernetes-mcp-server", JWKSFile: jwksFile, UsernamePrefix: OIDCPrefixDisabled, GroupsPrefix: OIDCPrefixDisabled,
+	})
+	t.Run("Authenticate with disabled prefixes returns user without prefixes", func(t *testing.T) {
+		user, err := disabled.Authenticate(context.Background(), sign("alice", []string{"team-a"}))
+		if err != nil {
+			t.Fatalf("failed to authenticate %v", err)
+		}
+		if user.Name != "alice" || !slices.Equal(user.Groups, []string{"team-a"}) {
+			t.Errorf("invalid user, got %s %v", user.Name, user.Groups)
+		}
+	})
+	t.Run("Authenticate with disabled prefixes drops system groups", func(t *testing.T) {
+		user, err := disabled.Authenticate(context.Background(), sign("alice", []string{"system:masters", "team-a", "system:authenticated"}))
+		if err != nil {
+			t.Fatalf("failed to authenticate %v", err)
+		}
+		if !slices.Equal(user.Groups, []string{"team-a"}) {
+			t.Errorf("expected system groups to be dropped, got %v", user.Groups)
+		}
+	})
+	t.Run("Authenticate with disabled prefixes rejects system users", func(t *testing.T) {
+		_, err := disabled.Authenticate(context.Background(), sign("system:serviceaccount:kube-system:admin", nil))
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+}
+
+func TestOIDCAuthenticatorWithoutAudience(t *testing.T) {
+	_, err := NewOIDCAuthenticator(context.Background(), OIDCOptions{IssuerURL: "https://issuer.example.com"})
+	t.Run("NewOIDCAuthenticator without audience returns error", func(t *testing.T) {
+		if err == nil || err.Error() != "OIDC audience is required" {
+			t.Fatalf("expected missing audience error, got %v", err)
+		}
+	})
+}
+
+func TestAuthenticators(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "tokens.csv")
+	_ = os.WriteFile(tokenFile, []byte("token-alice,alice\n"), 0600)
+	tokenFileAuthenticator, _ := NewTokenFileAuthenticator(tokenFile)
+	authenticators := Authenticators{tokenFileAuthenticator}
+	t.Run("Authenticators returns user from any authenticator", func(t *testing.T) {
+		if user, err := authenticators.Authenticate(context.Background(), "token-alice"); err != nil || user.Name != "alice" {
+			t.Fatalf("failed to authenticate %v %v", user, err)
+		}
+	})
+	t.Run("Authenticators returns ErrUnauthorized if no authenticator succeeds", func(t *testing.T) {
+		if _, err := authenticators.Authenticate(context.Background(), "token-eve"); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v4"
+	"os"
+	"strings"
+)
+
+// OIDCPrefixDisabled disables the username or groups prefix (as the kube-apiserver "-" prefix value)
+const OIDCPrefixDisabled = "-"
+
+// systemPrefix is the prefix of the Kubernetes reserved user and group names (e.g. system:masters)
+const systemPrefix = "system:"
+
+// OIDCOptions are the options to authenticate the JWTs issued by an OIDC provider
+type OIDCOptions struct {
+	// IssuerURL is the URL of the OIDC issuer
+	IssuerURL string
+	// Audience that the JWT aud claim must contain, otherwise tokens issued for any other client of the issuer would be accepted
+	Audience string
+	// JWKSFile is the path of a file with the signing keys (retrieved from the issuer's JWKS endpoint if empty)
+	JWKSFile string
+	// UsernamePrefix is prepended to the sub claim (IssuerURL# if empty, no prefix if OIDCPrefixDisabled)
+	UsernamePrefix string
+	// GroupsPrefix is prepended to each of the groups claim values (IssuerURL# if empty, no prefix if OIDCPrefixDisabled)
+	GroupsPrefix string
+}
+
+// oidcAuthenticator authenticates JWTs issued by an OIDC provider
+type oidcAuthenticator struct {
+	verifier       *oidc.IDTokenVerifier
+	usernamePrefix string
+	groupsPrefix   string
+}
+
+type oidcClaims struct {
+	Subject string   `json:"sub"`
+	Groups  []string `json:"groups"`
+}
+
+// NewOIDCAuthenticator creates an Authenticator for the JWTs issued by the provided OIDC issuer.
+// The signing keys are retrieved from the issuer's JWKS endpoint (discovered from its configuration),
+// or from the JWKS file if provided (no network access to the issuer is required).
+// As in the kube-apiserver OIDC authentication, the user and groups are prefixed with the issuer URL by default
+// so that they can't match the Kubernetes users, service accounts, or groups (e.g. system:masters).
+func NewOIDCAuthenticator(ctx context.Context, options OIDCOptions) (Authenticator, error) {
+	if options.Audience == "" {
+		return nil, errors.New("OIDC audience is required")
+	}
+	authenticator := &oidcAuthenticator{
+		usernamePrefix: oidcPrefix(options.UsernamePrefix, options.IssuerURL),
+		groupsPrefix:   oidcPrefix(options.GroupsPrefix, options.IssuerURL),
+	}
+	config := &oidc.Config{ClientID: options.Audience}
+	if options.JWKSFile != "" {
+		keySet, err := readJwksFile(options.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticator.verifier = oidc.NewVerifier(options.IssuerURL, keySet, config)
+		return authenticator, nil
+	}
+	provider, err := oidc.NewProvider(ctx, options.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer %s: %w", options.IssuerURL, err)
+	}
+	authenticator.verifier = provider.Verifier(config)
+	return authenticator, nil
+}
+
+// oidcPrefix resolves the username or groups prefix, IssuerURL# by default
+func oidcPrefix(prefix, issuerUrl string) string {
+	switch prefix {
+	case "":
+		return issuerUrl + "#"
+	case OIDCPrefixDisabled:
+		return ""
+	default:
+		return prefix
+	}
+}
+
+func (a *oidcAuthenticator) Authenticate(ctx context.Context, token string) (*User, error) {
+	idToken, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+	claims := oidcClaims{}
+	if err = idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+	name := a.usernamePrefix + claims.Subject
+	if strings.HasPrefix(name, systemPrefix) {
+		return nil, fmt.Errorf("%w: the user %s is reserved for Kubernetes components", ErrUnauthorized, name)
+	}
+	// The reserved groups are dropped (only possible if the groups prefix is disabled) so that the identity provider can't grant them
+	groups := make([]string, 0, len(claims.Groups))
+	for _, group := range claims.Groups {
+		if group = a.groupsPrefix + group; !strings.HasPrefix(group, systemPrefix) {
+			groups = append(groups, group)
+		}
+	}
+	return &User{Name: name, Groups: groups, Token: token, Passthrough: true}, nil
+}
+
+func readJwksFile(jwksFile string) (*oidc.StaticKeySet, error) {
+	data, err := os.ReadFile(jwksFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file %s: %w", jwksFile, err)
+	}
+	jwks := jose.JSONWebKeySet{}
+	if err = json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file %s: %w", jwksFile, err)
+	}
+	keySet := &oidc.StaticKeySet{}
+	for _, key := range jwks.Keys {
+		if key.IsPublic() {
+			keySet.PublicKeys = append(keySet.PublicKeys, crypto.PublicKey(key.Key))
+		} else {
+			keySet.PublicKeys = append(keySet.PublicKeys, key.Public().Key)
+		}
+	}
+	if len(keySet.PublicKeys) == 0 {
+		return nil, fmt.Errorf("invalid JWKS file %s, no keys found", jwksFile)
+	}
+	return keySet, nil
+}
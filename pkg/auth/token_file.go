@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tokenFileAuthenticator authenticates static tokens read from a CSV file with the same format as the
+// kube-apiserver --token-auth-file: token,user,uid,"group1,group2"
+type tokenFileAuthenticator struct {
+	users map[string]*User
+}
+
+// NewTokenFileAuthenticator creates an Authenticator for the static tokens in the provided file
+func NewTokenFileAuthenticator(tokenFile string) (Authenticator, error) {
+	f, err := os.Open(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token file %s: %w", tokenFile, err)
+	}
+	defer func() { _ = f.Close() }()
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file %s: %w", tokenFile, err)
+	}
+	users := make(map[string]*User)
+	for i, record := range records {
+		if len(record) < 2 || record[0] == "" || record[1] == "" {
+			return nil, fmt.Errorf("invalid token file %s, line %d: expected at least token and user", tokenFile, i+1)
+		}
+		user := &User{Name: record[1]}
+		if len(record) > 3 && record[3] != "" {
+			user.Groups = strings.Split(record[3], ",")
+		}
+		users[record[0]] = user
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("invalid token file %s, no tokens found", tokenFile)
+	}
+	return &tokenFileAuthenticator{users: users}, nil
+}
+
+func (a *tokenFileAuthenticator) Authenticate(_ context.Context, token string) (*User, error) {
+	for t, user := range a.users {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return &User{Name: user.Name, Groups: user.Groups, Token: token}, nil
+		}
+	}
+	return nil, ErrUnauthorized
+}
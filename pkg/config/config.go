@@ -0,0 +1,56 @@
+package config
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"path/filepath"
+)
+
+type CloseWatchConfig func() error
+
+// Read loads the provided configuration file into the global viper configuration.
+// The format (TOML, YAML or JSON) is inferred from the file extension.
+// The configuration keys are the same as the command line flags (e.g. read-only, enabled-tools),
+// flags provided in the command line take precedence over the configuration file values.
+func Read(configFile string) error {
+	viper.SetConfigFile(configFile)
+	return viper.ReadInConfig()
+}
+
+// Watch calls onConfigChange whenever the provided configuration file changes.
+// The parent directory is watched so that atomic saves and Kubernetes ConfigMap volume updates (symlink swaps)
+// are also detected.
+func Watch(configFile string, onConfigChange func() error) (CloseWatchConfig, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	configFile = filepath.Clean(configFile)
+	if err = watcher.Add(filepath.Dir(configFile)); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+	realConfigFile, _ := filepath.EvalSymlinks(configFile)
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				currentConfigFile, _ := filepath.EvalSymlinks(configFile)
+				modified := filepath.Clean(event.Name) == configFile && (event.Has(fsnotify.Write) || event.Has(fsnotify.Create))
+				relinked := currentConfigFile != "" && currentConfigFile != realConfigFile
+				if modified || relinked {
+					realConfigFile = currentConfigFile
+					_ = onConfigChange()
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return watcher.Close, nil
+}
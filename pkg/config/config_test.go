@@ -0,0 +1,120 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReadToml(t *testing.T) {
+	defer viper.Reset()
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	_ = os.WriteFile(configFile, []byte(`
+log-level = 2
+sse-port = 8080
+read-only = true
+kubeconfig = "/etc/kubeconfig"
+enabled-tools = ["pods_list", "pods_log"]
+`), 0644)
+	err := Read(configFile)
+	t.Run("Read toml configuration file succeeds", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("failed to read configuration %v", err)
+		}
+	})
+	t.Run("Read toml configuration file sets values", func(t *testing.T) {
+		if viper.GetInt("log-level") != 2 {
+			t.Errorf("invalid log-level, expected 2, got %v", viper.GetInt("log-level"))
+		}
+		if viper.GetInt("sse-port") != 8080 {
+			t.Errorf("invalid sse-port, expected 8080, got %v", viper.GetInt("sse-port"))
+		}
+		if !viper.GetBool("read-only") {
+			t.Errorf("invalid read-only, expected true")
+		}
+		if viper.GetString("kubeconfig") != "/etc/kubeconfig" {
+			t.Errorf("invalid kubeconfig, expected /etc/kubeconfig, got %v", viper.GetString("kubeconfig"))
+		}
+		if !slices.Equal(viper.GetStringSlice("enabled-tools"), []string{"pods_list", "pods_log"}) {
+			t.Errorf("invalid enabled-tools, got %v", viper.GetStringSlice("enabled-tools"))
+		}
+	})
+}
+
+func TestReadYaml(t *testing.T) {
+	defer viper.Reset()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	_ = os.WriteFile(configFile, []byte(`
+read-only: true
+disabled-tools:
+  - pods_exec
+  - pods_run
+`), 0644)
+	err := Read(configFile)
+	t.Run("Read yaml configuration file succeeds", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("failed to read configuration %v", err)
+		}
+	})
+	t.Run("Read yaml configuration file sets values", func(t *testing.T) {
+		if !viper.GetBool("read-only") {
+			t.Errorf("invalid read-only, expected true")
+		}
+		if !slices.Equal(viper.GetStringSlice("disabled-tools"), []string{"pods_exec", "pods_run"}) {
+			t.Errorf("invalid disabled-tools, got %v", viper.GetStringSlice("disabled-tools"))
+		}
+	})
+}
+
+func TestReadInvalid(t *testing.T) {
+	defer viper.Reset()
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	_ = os.WriteFile(configFile, []byte("read-only = = true"), 0644)
+	t.Run("Read invalid configuration file returns error", func(t *testing.T) {
+		if err := Read(configFile); err == nil {
+			t.Fatalf("expected error for invalid configuration")
+		}
+	})
+	t.Run("Read missing configuration file returns error", func(t *testing.T) {
+		if err := Read(filepath.Join(t.TempDir(), "missing.toml")); err == nil {
+			t.Fatalf("expected error for missing configuration")
+		}
+	})
+}
+
+func TestWatch(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Skipping test on non-linux platforms")
+	}
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	_ = os.WriteFile(configFile, []byte("read-only = false\n"), 0644)
+	changed := make(chan struct{}, 10)
+	closeWatchConfig, err := Watch(configFile, func() error {
+		changed <- struct{}{}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to watch configuration %v", err)
+	}
+	defer func() { _ = closeWatchConfig() }()
+	t.Run("Watch ignores changes to other files", func(t *testing.T) {
+		_ = os.WriteFile(filepath.Join(filepath.Dir(configFile), "other.toml"), []byte("\n"), 0644)
+		select {
+		case <-changed:
+			t.Fatalf("Watch notified a change for another file")
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+	t.Run("Watch notifies configuration file changes", func(t *testing.T) {
+		_ = os.WriteFile(configFile, []byte("read-only = true\n"), 0644)
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Watch did not notify the configuration change")
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	auditLogPath := filepath.Join(t.TempDir(), "audit.log")
+	closeAuditLog, err := audit.Init(audit.Options{Path: auditLogPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closeAuditLog() }()
+	testCaseWithContext(t, &httpContext{options: Options{Authenticator: staticAuthenticator{"valid-token": "alice"}}}, func(c *httpContext) {
+		mcpClient, err := client.NewStreamableHttpClient(c.baseUrl+"/mcp",
+			transport.WithHTTPHeaders(map[string]string{"Authorization": "Bearer valid-token"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = mcpClient.Close() }()
+		if err = initialize(c.ctx, mcpClient); err != nil {
+			t.Fatal(err)
+		}
+		callToolRequest := mcpgo.CallToolRequest{}
+		callToolRequest.Params.Name = "pods_log"
+		callToolRequest.Params.Arguments = map[string]any{"namespace": "default", "name": "a-pod", "token": "a-token"}
+		if _, err = mcpClient.CallTool(c.ctx, callToolRequest); err != nil {
+			t.Fatal(err)
+		}
+		auditLog, err := os.ReadFile(auditLogPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(auditLog)), "\n")
+		record := map[string]any{}
+		if err = json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+			t.Fatalf("invalid audit log record %s: %v", lines[len(lines)-1], err)
+		}
+		t.Run("Audit log records tool and authenticated user", func(t *testing.T) {
+			if record["tool"] != "pods_log" || record["user"] != "alice" {
+				t.Fatalf("invalid audit log record, got %v", record)
+			}
+		})
+		t.Run("Audit log records session id", func(t *testing.T) {
+			if record["sessionId"] == nil || record["sessionId"] == "" {
+				t.Fatalf("expected session id, got %v", record)
+			}
+		})
+		t.Run("Audit log records target cluster and namespace", func(t *testing.T) {
+			if record["cluster"] != c.kubeApiServer.URL || record["namespace"] != "default" {
+				t.Fatalf("invalid target cluster or namespace, got %v", record)
+			}
+		})
+		t.Run("Audit log records outcome and reason", func(t *testing.T) {
+			if record["outcome"] != "error" || record["reason"] != "NotFound" {
+				t.Fatalf("invalid outcome or reason, got %v", record)
+			}
+		})
+		t.Run("Audit log redacts sensitive arguments", func(t *testing.T) {
+			if record["arguments"].(map[string]any)["token"] != "[REDACTED]" {
+				t.Fatalf("expected token to be redacted, got %v", record["arguments"])
+			}
+		})
+	})
+}
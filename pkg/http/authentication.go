@@ -0,0 +1,37 @@
+package http
+
+import (
+	"github.com/manusa/kubernetes-mcp-server/pkg/auth"
+	"k8s.io/klog/v2"
+	"net/http"
+)
+
+// AuthenticationMiddleware rejects with 401 any request without a valid bearer token.
+// The authenticated user is added to the request context (see auth.UserFrom).
+// If authenticator is nil, requests are not authenticated.
+func AuthenticationMiddleware(authenticator auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if authenticator == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := auth.BearerToken(r)
+			if token == "" {
+				unauthorized(w, "missing bearer token")
+				return
+			}
+			user, err := authenticator.Authenticate(r.Context(), token)
+			if err != nil {
+				klog.V(2).Infof("Authentication failed for %s %s from %s: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+				unauthorized(w, "invalid bearer token")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, reason string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="kubernetes-mcp-server"`)
+	http.Error(w, "Unauthorized: "+reason, http.StatusUnauthorized)
+}
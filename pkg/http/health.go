@@ -0,0 +1,50 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/manusa/kubernetes-mcp-server/pkg/mcp"
+	"github.com/manusa/kubernetes-mcp-server/pkg/version"
+	"k8s.io/klog/v2"
+	"net/http"
+	"time"
+)
+
+const (
+	healthEndpoint    = "/healthz"
+	readinessEndpoint = "/readyz"
+	versionEndpoint   = "/version"
+	readinessTimeout  = 5 * time.Second
+)
+
+// healthHandler reports that the process is alive
+func healthHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok"))
+}
+
+// readinessHandler reports whether the MCP server is ready (Kubernetes clients loaded and API server reachable)
+func readinessHandler(mcpServer *mcp.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+		if err := mcpServer.Ready(ctx); err != nil {
+			klog.V(2).Infof("Readiness check failed: %v", err)
+			http.Error(w, "not ready: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok"))
+	}
+}
+
+// versionHandler reports the server version information
+func versionHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"name":       version.BinaryName,
+		"version":    version.Version,
+		"commitHash": version.CommitHash,
+		"buildTime":  version.BuildTime,
+	})
+}
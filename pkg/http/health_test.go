@@ -0,0 +1,110 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	testCaseWithContext(t, &httpContext{options: Options{Authenticator: staticAuthenticator{"valid-token": "alice"}}}, func(c *httpContext) {
+		resp, err := http.Get(c.baseUrl + "/healthz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		t.Run("healthz returns 200 without authentication", func(t *testing.T) {
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected 200, got %d", resp.StatusCode)
+			}
+		})
+		t.Run("healthz returns ok", func(t *testing.T) {
+			if string(body) != "ok" {
+				t.Fatalf("expected ok, got %s", body)
+			}
+		})
+	})
+}
+
+func TestReadiness(t *testing.T) {
+	testCase(t, func(c *httpContext) {
+		t.Run("readyz returns 200 when the Kubernetes API server is reachable", func(t *testing.T) {
+			resp, err := http.Get(c.baseUrl + "/readyz")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected 200, got %d", resp.StatusCode)
+			}
+		})
+		t.Run("readyz returns 503 when the Kubernetes API server is not reachable", func(t *testing.T) {
+			c.kubeApiServer.Close()
+			resp, err := http.Get(c.baseUrl + "/readyz")
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusServiceUnavailable {
+				t.Fatalf("expected 503, got %d", resp.StatusCode)
+			}
+			if len(body) == 0 {
+				t.Fatalf("expected reason in response body")
+			}
+		})
+	})
+}
+
+func TestReadinessWithInvalidKubeConfig(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Skipping test on non-linux platforms")
+	}
+	testCase(t, func(c *httpContext) {
+		_ = os.WriteFile(c.kubeConfigFile, []byte("invalid: kubeconfig: content"), 0600)
+		t.Run("readyz returns 503 when the kubeconfig can't be loaded", func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				resp, err := http.Get(c.baseUrl + "/readyz")
+				if err != nil {
+					t.Fatal(err)
+				}
+				_ = resp.Body.Close()
+				if resp.StatusCode == http.StatusServiceUnavailable {
+					return
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+			t.Fatalf("readyz did not report the invalid kubeconfig")
+		})
+	})
+}
+
+func TestVersion(t *testing.T) {
+	testCaseWithContext(t, &httpContext{options: Options{Authenticator: staticAuthenticator{"valid-token": "alice"}}}, func(c *httpContext) {
+		resp, err := http.Get(c.baseUrl + "/version")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&decoded)
+		_ = resp.Body.Close()
+		t.Run("version returns 200 without authentication", func(t *testing.T) {
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected 200, got %d", resp.StatusCode)
+			}
+		})
+		t.Run("version returns version information", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("invalid version response %v", err)
+			}
+			if decoded["name"] != "kubernetes-mcp-server" || decoded["version"] != "0.0.0" {
+				t.Fatalf("invalid version information, got %v", decoded)
+			}
+		})
+	})
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/auth"
+	"github.com/manusa/kubernetes-mcp-server/pkg/mcp"
+	"github.com/manusa/kubernetes-mcp-server/pkg/metrics"
+	"k8s.io/klog/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	mcpEndpoint        = "/mcp"
+	sseEndpoint        = "/sse"
+	sseMessageEndpoint = "/message"
+	metricsEndpoint    = "/metrics"
+	shutdownTimeout    = 10 * time.Second
+)
+
+type Options struct {
+	// Port is the port the HTTP server listens on
+	Port int
+	// SseBaseUrl is the public base URL sent to the SSE clients in the endpoint event
+	SseBaseUrl string
+	// SseOnly disables the Streamable HTTP transport (legacy --sse-port mode)
+	SseOnly bool
+	// Authenticator validates the bearer token of every request, authentication is disabled if nil
+	Authenticator auth.Authenticator
+	// TLSCertFile and TLSKeyFile enable TLS, the certificate is reloaded whenever the files change
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile enables client certificate verification (mTLS) against the provided CA bundle
+	TLSClientCAFile string
+}
+
+// Serve starts an HTTP server on the provided port exposing the Streamable HTTP transport (/mcp)
+// and the legacy SSE transport (/sse and /message) for backwards compatibility.
+// It blocks until the provided context is done or the process receives a termination signal.
+func Serve(ctx context.Context, mcpServer *mcp.Server, options Options) error {
+	mux := http.NewServeMux()
+	mcpMux := http.NewServeMux()
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", options.Port),
+		Handler: mux,
+	}
+	if options.TLSCertFile != "" || options.TLSKeyFile != "" {
+		if options.TLSCertFile == "" || options.TLSKeyFile == "" {
+			return errors.New("both TLS certificate and key files must be provided")
+		}
+		reloader, err := newCertificateReloader(options.TLSCertFile, options.TLSKeyFile)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = reloader.Close() }()
+		if httpServer.TLSConfig, err = tlsConfig(reloader, options.TLSClientCAFile); err != nil {
+			return err
+		}
+	} else if options.TLSClientCAFile != "" {
+		return errors.New("TLS client CA file requires TLS certificate and key files")
+	}
+	sseServer := mcpServer.ServeSse(options.SseBaseUrl)
+	mcpMux.Handle(sseEndpoint, sseServer)
+	mcpMux.Handle(sseMessageEndpoint, sseServer)
+	if !options.SseOnly {
+		mcpMux.Handle(mcpEndpoint, mcpServer.ServeHTTP())
+	}
+	// Probes, version information and metrics are served without authentication
+	mux.HandleFunc(healthEndpoint, healthHandler)
+	mux.HandleFunc(readinessEndpoint, readinessHandler(mcpServer))
+	mux.HandleFunc(versionEndpoint, versionHandler)
+	mux.Handle(metricsEndpoint, metrics.Handler())
+	var handler http.Handler = AuthenticationMiddleware(options.Authenticator)(mcpMux)
+	if options.TLSClientCAFile != "" {
+		handler = clientCertificateMiddleware(handler)
+	}
+	mux.Handle("/", handler)
+	if options.Authenticator == nil && options.TLSClientCAFile == "" {
+		klog.Warningf("HTTP server authentication is disabled, any client reaching port %d can use the server credentials", options.Port)
+	}
+
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	serverErr := make(chan error, 1)
+	go func() {
+		if options.SseOnly {
+			klog.V(0).Infof("SSE server starting on port %d", options.Port)
+		} else {
+			klog.V(0).Infof("HTTP server starting on port %d (Streamable HTTP: %s, SSE: %s)", options.Port, mcpEndpoint, sseEndpoint)
+		}
+		var err error
+		if httpServer.TLSConfig != nil {
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			return fmt.Errorf("failed to start HTTP server: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+	klog.V(0).Infof("HTTP server shutting down")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	// SSE connections are long-lived, close the sessions so that the HTTP server can shut down gracefully
+	_ = sseServer.Shutdown(shutdownCtx)
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/auth"
+	"github.com/manusa/kubernetes-mcp-server/pkg/mcp"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type httpContext struct {
+	options        Options
+	kubeApiServer  *httptest.Server
+	kubeConfigFile string
+	ctx            context.Context
+	cancel         context.CancelFunc
+	mcpServer      *mcp.Server
+	baseUrl        string
+	serveError     chan error
+}
+
+func (c *httpContext) beforeEach(t *testing.T) {
+	// Fake Kubernetes API server that only serves the version endpoint
+	c.kubeApiServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/version" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"major":"1","minor":"32","gitVersion":"v1.32.3"}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	kubeConfig := api.NewConfig()
+	kubeConfig.Clusters["fake"] = api.NewCluster()
+	kubeConfig.Clusters["fake"].Server = c.kubeApiServer.URL
+	kubeConfig.AuthInfos["fake"] = api.NewAuthInfo()
+	kubeConfig.Contexts["fake-context"] = api.NewContext()
+	kubeConfig.Contexts["fake-context"].Cluster = "fake"
+	kubeConfig.Contexts["fake-context"].AuthInfo = "fake"
+	kubeConfig.CurrentContext = "fake-context"
+	c.kubeConfigFile = filepath.Join(t.TempDir(), "config")
+	if err := clientcmd.WriteToFile(*kubeConfig, c.kubeConfigFile); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	if c.mcpServer, err = mcp.NewSever(mcp.Configuration{Kubeconfig: c.kubeConfigFile}); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+	c.options.Port = port
+	c.baseUrl = fmt.Sprintf("http://127.0.0.1:%d", port)
+	if c.options.TLSCertFile != "" {
+		c.baseUrl = fmt.Sprintf("https://127.0.0.1:%d", port)
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.serveError = make(chan error, 1)
+	go func() { c.serveError <- Serve(c.ctx, c.mcpServer, c.options) }()
+	for i := 0; i < 50; i++ {
+		if conn, dialErr := net.Dial("tcp", listener.Addr().String()); dialErr == nil {
+			_ = conn.Close()
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("HTTP server did not start")
+}
+
+func (c *httpContext) afterEach() {
+	c.cancel()
+	c.mcpServer.Close()
+	c.kubeApiServer.Close()
+}
+
+func testCase(t *testing.T, test func(c *httpContext)) {
+	testCaseWithContext(t, &httpContext{}, test)
+}
+
+func testCaseWithContext(t *testing.T, c *httpContext, test func(c *httpContext)) {
+	c.beforeEach(t)
+	defer c.afterEach()
+	test(c)
+}
+
+func initialize(ctx context.Context, mcpClient *client.Client) error {
+	if err := mcpClient.Start(ctx); err != nil {
+		return err
+	}
+	initRequest := mcpgo.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcpgo.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcpgo.Implementation{Name: "test", Version: "1.33.7"}
+	_, err := mcpClient.Initialize(ctx, initRequest)
+	return err
+}
+
+func TestServeStreamableHttp(t *testing.T) {
+	testCase(t, func(c *httpContext) {
+		mcpClient, err := client.NewStreamableHttpClient(c.baseUrl + "/mcp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = mcpClient.Close() }()
+		err = initialize(c.ctx, mcpClient)
+		t.Run("Streamable HTTP client initializes session at /mcp", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("failed to initialize %v", err)
+			}
+		})
+		tools, err := mcpClient.ListTools(c.ctx, mcpgo.ListToolsRequest{})
+		t.Run("Streamable HTTP client lists tools", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("failed to list tools %v", err)
+			}
+			if len(tools.Tools) == 0 {
+				t.Fatalf("expected tools, got none")
+			}
+		})
+	})
+}
+
+func TestServeSse(t *testing.T) {
+	testCase(t, func(c *httpContext) {
+		mcpClient, err := client.NewSSEMCPClient(c.baseUrl + "/sse")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = mcpClient.Close() }()
+		err = initialize(c.ctx, mcpClient)
+		t.Run("SSE client initializes session at /sse and /message", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("failed to initialize %v", err)
+			}
+		})
+		tools, err := mcpClient.ListTools(c.ctx, mcpgo.ListToolsRequest{})
+		t.Run("SSE client lists tools", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("failed to list tools %v", err)
+			}
+			if len(tools.Tools) == 0 {
+				t.Fatalf("expected tools, got none")
+			}
+		})
+	})
+}
+
+func TestServeUnknownEndpoint(t *testing.T) {
+	testCase(t, func(c *httpContext) {
+		resp, err := http.Get(c.baseUrl + "/unknown")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		t.Run("Unknown endpoint returns 404", func(t *testing.T) {
+			if resp.StatusCode != http.StatusNotFound {
+				t.Fatalf("expected 404, got %d", resp.StatusCode)
+			}
+		})
+	})
+}
+
+func TestServeShutdown(t *testing.T) {
+	testCase(t, func(c *httpContext) {
+		c.cancel()
+		t.Run("Serve returns when the context is cancelled", func(t *testing.T) {
+			select {
+			case err := <-c.serveError:
+				if err != nil {
+					t.Fatalf("expected no error on shutdown, got %v", err)
+				}
+			case <-time.After(15 * time.Second):
+				t.Fatalf("Serve did not return after the context was cancelled")
+			}
+		})
+	})
+}
+
+type staticAuthenticator map[string]string
+
+func (a staticAuthenticator) Authenticate(_ context.Context, token string) (*auth.User, error) {
+	if user, ok := a[token]; ok {
+		return &auth.User{Name: user, Token: token}, nil
+	}
+	return nil, auth.ErrUnauthorized
+}
+
+func TestServeWithAuthentication(t *testing.T) {
+	testCaseWithContext(t, &httpContext{options: Options{Authenticator: staticAuthenticator{"valid-token": "alice"}}}, func(c *httpContext) {
+		for _, endpoint := range []string{"/mcp", "/sse", "/message"} {
+			t.Run("Request to "+endpoint+" without bearer token returns 401", func(t *testing.T) {
+				resp, err := http.Get(c.baseUrl + endpoint)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_ = resp.Body.Close()
+				if resp.StatusCode != http.StatusUnauthorized {
+					t.Fatalf("expected 401, got %d", resp.StatusCode)
+				}
+				if resp.Header.Get("WWW-Authenticate") != `Bearer realm="kubernetes-mcp-server"` {
+					t.Fatalf("invalid WWW-Authenticate header, got %s", resp.Header.Get("WWW-Authenticate"))
+				}
+			})
+		}
+		t.Run("Request with invalid bearer token returns 401", func(t *testing.T) {
+			req, _ := http.NewRequest("GET", c.baseUrl+"/sse", nil)
+			req.Header.Set("Authorization", "Bearer invalid-token")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d", resp.StatusCode)
+			}
+		})
+		t.Run("Streamable HTTP client with valid bearer token initializes", func(t *testing.T) {
+			mcpClient, err := client.NewStreamableHttpClient(c.baseUrl+"/mcp",
+				transport.WithHTTPHeaders(map[string]string{"Authorization": "Bearer valid-token"}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = mcpClient.Close() }()
+			if err = initialize(c.ctx, mcpClient); err != nil {
+				t.Fatalf("failed to initialize %v", err)
+			}
+		})
+		t.Run("SSE client with valid bearer token initializes", func(t *testing.T) {
+			mcpClient, err := client.NewSSEMCPClient(c.baseUrl+"/sse",
+				transport.WithHeaders(map[string]string{"Authorization": "Bearer valid-token"}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = mcpClient.Close() }()
+			if err = initialize(c.ctx, mcpClient); err != nil {
+				t.Fatalf("failed to initialize %v", err)
+			}
+		})
+	})
+}
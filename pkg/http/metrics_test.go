@@ -0,0 +1,61 @@
+package http
+
+import (
+	"github.com/mark3labs/mcp-go/client"
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	testCaseWithContext(t, &httpContext{options: Options{Authenticator: staticAuthenticator{"valid-token": "alice"}}}, func(c *httpContext) {
+		resp, err := http.Get(c.baseUrl + "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		t.Run("metrics returns 200 without authentication", func(t *testing.T) {
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected 200, got %d", resp.StatusCode)
+			}
+		})
+	})
+	testCase(t, func(c *httpContext) {
+		mcpClient, err := client.NewStreamableHttpClient(c.baseUrl + "/mcp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = mcpClient.Close() }()
+		if err = initialize(c.ctx, mcpClient); err != nil {
+			t.Fatal(err)
+		}
+		callToolRequest := mcpgo.CallToolRequest{}
+		callToolRequest.Params.Name = "configuration_view"
+		if _, err = mcpClient.CallTool(c.ctx, callToolRequest); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.Get(c.baseUrl + "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		t.Run("metrics contains the tool call counter", func(t *testing.T) {
+			if !strings.Contains(string(body), `kubernetes_mcp_server_tool_calls_total{result="success",tool="configuration_view"}`) {
+				t.Fatalf("expected tool call counter in metrics, got %s", body)
+			}
+		})
+		t.Run("metrics contains the tool call latency histogram", func(t *testing.T) {
+			if !strings.Contains(string(body), `kubernetes_mcp_server_tool_call_duration_seconds_count{tool="configuration_view"}`) {
+				t.Fatalf("expected tool call latency histogram in metrics, got %s", body)
+			}
+		})
+		t.Run("metrics contains the Kubernetes API request counter", func(t *testing.T) {
+			if !strings.Contains(string(body), "kubernetes_mcp_server_kubernetes_requests_total{") {
+				t.Fatalf("expected Kubernetes API request counter in metrics, got %s", body)
+			}
+		})
+	})
+}
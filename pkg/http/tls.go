@@ -0,0 +1,104 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/config"
+	"k8s.io/klog/v2"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// certificateReloader serves the TLS certificate and reloads it whenever the certificate or key files change
+// (e.g. certificate rotation by cert-manager or a Kubernetes Secret volume update).
+type certificateReloader struct {
+	certFile    string
+	keyFile     string
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+	closers     []config.CloseWatchConfig
+}
+
+func newCertificateReloader(certFile, keyFile string) (*certificateReloader, error) {
+	r := &certificateReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	for _, file := range []string{certFile, keyFile} {
+		closeWatch, err := config.Watch(file, func() error {
+			// The certificate and key might not be updated atomically, keep serving the previous certificate until both match
+			if err := r.reload(); err != nil {
+				klog.Errorf("Failed to reload TLS certificate: %s", err)
+				return err
+			}
+			klog.V(1).Infof("TLS certificate reloaded from %s", r.certFile)
+			return nil
+		})
+		if err != nil {
+			_ = r.Close()
+			return nil, fmt.Errorf("failed to watch TLS certificate file %s: %w", file, err)
+		}
+		r.closers = append(r.closers, closeWatch)
+	}
+	return r, nil
+}
+
+func (r *certificateReloader) reload() error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate %s and key %s: %w", r.certFile, r.keyFile, err)
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.certificate = &certificate
+	return nil
+}
+
+func (r *certificateReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.certificate, nil
+}
+
+func (r *certificateReloader) Close() error {
+	var errs []error
+	for _, closeWatch := range r.closers {
+		errs = append(errs, closeWatch())
+	}
+	return errors.Join(errs...)
+}
+
+// tlsConfig returns the server TLS configuration, clients must provide a certificate signed by clientCAFile if provided (mTLS)
+func tlsConfig(reloader *certificateReloader, clientCAFile string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if clientCAFile != "" {
+		clientCAs, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS client CA file %s: %w", clientCAFile, err)
+		}
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(clientCAs) {
+			return nil, fmt.Errorf("invalid TLS client CA file %s, no certificates found", clientCAFile)
+		}
+		// Client certificates are verified if provided, clientCertificateMiddleware requires them for any non-probe request
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return cfg, nil
+}
+
+// clientCertificateMiddleware rejects with 401 any request without a verified client certificate (mTLS)
+func clientCertificateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			http.Error(w, "Unauthorized: client certificate required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
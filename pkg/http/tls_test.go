@@ -0,0 +1,197 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	certPem     []byte
+	keyPem      []byte
+}
+
+// generateCertificate creates a certificate signed by the provided CA (self-signed CA if nil)
+func generateCertificate(t *testing.T, commonName string, ca *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	parent, parentKey := template, key
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		parent, parentKey = ca.certificate, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, _ := x509.ParseCertificate(der)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	return &testCertificate{
+		certificate: certificate,
+		key:         key,
+		certPem:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPem:      pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}
+}
+
+func (c *testCertificate) write(t *testing.T, certFile, keyFile string) {
+	if err := os.WriteFile(certFile, c.certPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, c.keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func tlsClient(ca *testCertificate, clientCertificate *testCertificate) *http.Client {
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.certificate)
+	tlsConfig := &tls.Config{RootCAs: rootCAs}
+	if clientCertificate != nil {
+		certificate, _ := tls.X509KeyPair(clientCertificate.certPem, clientCertificate.keyPem)
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true}}
+}
+
+func TestServeTLS(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Skipping test on non-linux platforms")
+	}
+	dir := t.TempDir()
+	ca := generateCertificate(t, "ca", nil)
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	generateCertificate(t, "server", ca).write(t, certFile, keyFile)
+	testCaseWithContext(t, &httpContext{options: Options{TLSCertFile: certFile, TLSKeyFile: keyFile}}, func(c *httpContext) {
+		t.Run("HTTPS request with trusted CA succeeds", func(t *testing.T) {
+			resp, err := tlsClient(ca, nil).Get(c.baseUrl + "/unknown")
+			if err != nil {
+				t.Fatalf("HTTPS request failed %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusNotFound {
+				t.Fatalf("expected 404, got %d", resp.StatusCode)
+			}
+		})
+		t.Run("Plain HTTP request fails", func(t *testing.T) {
+			resp, err := http.Get("http" + c.baseUrl[len("https"):] + "/unknown")
+			if err == nil {
+				_ = resp.Body.Close()
+				if resp.StatusCode != http.StatusBadRequest {
+					t.Fatalf("expected plain HTTP request to be rejected, got %d", resp.StatusCode)
+				}
+			}
+		})
+		t.Run("Certificate is reloaded when the files change", func(t *testing.T) {
+			rotated := generateCertificate(t, "rotated-server", ca)
+			rotated.write(t, certFile, keyFile)
+			for i := 0; i < 50; i++ {
+				resp, err := tlsClient(ca, nil).Get(c.baseUrl + "/unknown")
+				if err == nil {
+					_ = resp.Body.Close()
+					if resp.TLS.PeerCertificates[0].Subject.CommonName == "rotated-server" {
+						return
+					}
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+			t.Fatalf("certificate was not reloaded")
+		})
+	})
+}
+
+func TestServeMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCertificate(t, "ca", nil)
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	generateCertificate(t, "server", ca).write(t, certFile, keyFile)
+	clientCAFile := filepath.Join(dir, "ca.crt")
+	_ = os.WriteFile(clientCAFile, ca.certPem, 0600)
+	options := Options{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: clientCAFile}
+	testCaseWithContext(t, &httpContext{options: options}, func(c *httpContext) {
+		t.Run("HTTPS request without client certificate returns 401", func(t *testing.T) {
+			resp, err := tlsClient(ca, nil).Get(c.baseUrl + "/unknown")
+			if err != nil {
+				t.Fatalf("HTTPS request failed %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d", resp.StatusCode)
+			}
+		})
+		t.Run("HTTPS probe request without client certificate succeeds", func(t *testing.T) {
+			resp, err := tlsClient(ca, nil).Get(c.baseUrl + "/healthz")
+			if err != nil {
+				t.Fatalf("HTTPS request failed %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected 200, got %d", resp.StatusCode)
+			}
+		})
+		t.Run("HTTPS request with untrusted client certificate is rejected", func(t *testing.T) {
+			untrusted := generateCertificate(t, "untrusted-client", generateCertificate(t, "another-ca", nil))
+			resp, err := tlsClient(ca, untrusted).Get(c.baseUrl + "/unknown")
+			// The client doesn't send certificates that don't match the server's acceptable CAs, the handshake fails otherwise
+			if err == nil {
+				_ = resp.Body.Close()
+				if resp.StatusCode != http.StatusUnauthorized {
+					t.Fatalf("expected request with untrusted client certificate to be rejected, got %d", resp.StatusCode)
+				}
+			}
+		})
+		t.Run("HTTPS request with trusted client certificate succeeds", func(t *testing.T) {
+			resp, err := tlsClient(ca, generateCertificate(t, "client", ca)).Get(c.baseUrl + "/unknown")
+			if err != nil {
+				t.Fatalf("HTTPS request failed %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusNotFound {
+				t.Fatalf("expected 404, got %d", resp.StatusCode)
+			}
+		})
+	})
+}
+
+func TestServeTLSInvalidConfiguration(t *testing.T) {
+	for name, options := range map[string]Options{
+		"certificate without key":     {TLSCertFile: "tls.crt"},
+		"key without certificate":     {TLSKeyFile: "tls.key"},
+		"client CA without TLS":       {TLSClientCAFile: "ca.crt"},
+		"missing certificate and key": {TLSCertFile: filepath.Join(t.TempDir(), "tls.crt"), TLSKeyFile: filepath.Join(t.TempDir(), "tls.key")},
+	} {
+		t.Run("Serve with "+name+" returns error", func(t *testing.T) {
+			if err := Serve(t.Context(), nil, options); err == nil {
+				t.Fatalf("expected error for invalid TLS configuration")
+			}
+		})
+	}
+}
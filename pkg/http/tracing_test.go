@@ -0,0 +1,88 @@
+package http
+
+import (
+	"github.com/mark3labs/mcp-go/client"
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"strings"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousTracerProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracerProvider)
+	defer otel.SetTracerProvider(previousTracerProvider)
+	testCase(t, func(c *httpContext) {
+		mcpClient, err := client.NewStreamableHttpClient(c.baseUrl + "/mcp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = mcpClient.Close() }()
+		if err = initialize(c.ctx, mcpClient); err != nil {
+			t.Fatal(err)
+		}
+		callToolRequest := mcpgo.CallToolRequest{}
+		callToolRequest.Params.Name = "pods_log"
+		callToolRequest.Params.Arguments = map[string]any{"namespace": "default", "name": "a-pod"}
+		if _, err = mcpClient.CallTool(c.ctx, callToolRequest); err != nil {
+			t.Fatal(err)
+		}
+		var toolSpan *tracetest.SpanStub
+		var kubernetesSpans []tracetest.SpanStub
+		for _, span := range exporter.GetSpans() {
+			if span.Name == "tools/call pods_log" {
+				toolSpan = &span
+			}
+		}
+		t.Run("Tool call creates span", func(t *testing.T) {
+			if toolSpan == nil {
+				t.Fatalf("expected tools/call pods_log span, got %v", exporter.GetSpans())
+			}
+		})
+		t.Run("Tool call span has tool arguments", func(t *testing.T) {
+			if toolSpan == nil {
+				t.Fatal("expected tools/call pods_log span, got none")
+			}
+			for _, attr := range toolSpan.Attributes {
+				if attr.Key == "mcp.tool.arguments" && attr.Value.AsString() == `{"name":"a-pod","namespace":"default"}` {
+					return
+				}
+			}
+			t.Fatalf("expected mcp.tool.arguments attribute, got %v", toolSpan.Attributes)
+		})
+		for _, span := range exporter.GetSpans() {
+			if toolSpan != nil && span.Parent.SpanID() == toolSpan.SpanContext.SpanID() {
+				kubernetesSpans = append(kubernetesSpans, span)
+			}
+		}
+		t.Run("Kubernetes requests create spans nested in the tool call span", func(t *testing.T) {
+			if len(kubernetesSpans) == 0 {
+				t.Fatalf("expected nested Kubernetes request spans, got none")
+			}
+			if kubernetesSpans[0].Name != "GET /api/v1/namespaces/default/pods/a-pod/log" {
+				t.Fatalf("expected GET /api/v1/namespaces/default/pods/a-pod/log span, got %s", kubernetesSpans[0].Name)
+			}
+		})
+		t.Run("Kubernetes request spans have the path without the query", func(t *testing.T) {
+			if len(kubernetesSpans) == 0 {
+				t.Fatal("expected nested Kubernetes request spans, got none")
+			}
+			path := ""
+			for _, attr := range kubernetesSpans[0].Attributes {
+				if strings.Contains(attr.Value.Emit(), "tailLines") {
+					t.Fatalf("expected no query in the span attributes, got %s=%s", attr.Key, attr.Value.Emit())
+				}
+				if attr.Key == "url.path" {
+					path = attr.Value.AsString()
+				}
+			}
+			if path != "/api/v1/namespaces/default/pods/a-pod/log" {
+				t.Fatalf("expected url.path attribute, got %v", kubernetesSpans[0].Attributes)
+			}
+		})
+	})
+}
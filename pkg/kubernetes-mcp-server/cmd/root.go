@@ -4,14 +4,22 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
+	"github.com/manusa/kubernetes-mcp-server/pkg/auth"
+	"github.com/manusa/kubernetes-mcp-server/pkg/config"
+	internalhttp "github.com/manusa/kubernetes-mcp-server/pkg/http"
+	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
 	"github.com/manusa/kubernetes-mcp-server/pkg/mcp"
+	"github.com/manusa/kubernetes-mcp-server/pkg/tracing"
 	"github.com/manusa/kubernetes-mcp-server/pkg/version"
-	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/net/context"
+	"io"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/textlogger"
+	"log/slog"
 	"os"
 	"strconv"
 )
@@ -31,34 +39,108 @@ Kubernetes Model Context Protocol (MCP) server
   # start STDIO server
   kubernetes-mcp-server
 
+  # start a Streamable HTTP server on port 8080 (http://localhost:8080/mcp), SSE is also served at http://localhost:8080/sse
+  kubernetes-mcp-server --port 8080
+
   # start a SSE server on port 8080
   kubernetes-mcp-server --sse-port 8080
 
   # start a SSE server on port 8443 with a public HTTPS host of example.com
   kubernetes-mcp-server --sse-port 8443 --sse-base-url https://example.com:8443
 
+  # start a Streamable HTTP server with TLS that requires client certificates signed by the provided CA (mTLS)
+  kubernetes-mcp-server --port 8443 --tls-cert-file tls.crt --tls-key-file tls.key --tls-client-ca-file ca.crt
+
+  # start a Streamable HTTP server that only accepts the bearer tokens in the provided file
+  kubernetes-mcp-server --port 8080 --auth-token-file /etc/kubernetes-mcp-server/tokens.csv
+
+  # start a Streamable HTTP server that only accepts JWTs issued by an OIDC provider for the provided audience
+  kubernetes-mcp-server --port 8080 --oidc-issuer-url https://accounts.example.com --oidc-audience kubernetes-mcp-server
+
+  # start a Streamable HTTP server that impersonates the authenticated OIDC users in the Kubernetes requests
+  kubernetes-mcp-server --port 8080 --oidc-issuer-url https://accounts.example.com --oidc-audience kubernetes-mcp-server --identity-mode impersonate
+
+  # start a Streamable HTTP server that writes JSON logs to a file
+  kubernetes-mcp-server --port 8080 --log-format json --log-file /var/log/kubernetes-mcp-server.log
+
+  # start a STDIO server that exposes read-only tools only
+  kubernetes-mcp-server --read-only
+
+  # start a STDIO server that exposes only the pods_list and pods_log tools
+  kubernetes-mcp-server --enabled-tools pods_list,pods_log
+
+  # start a STDIO server that denies access to Secrets and any RBAC resource
+  kubernetes-mcp-server --denied-resources "v1 Secret,rbac.authorization.k8s.io/* *"
+
+  # start a STDIO server restricted to the team-a namespace and any namespace starting with team-a-
+  kubernetes-mcp-server --allowed-namespaces "team-a,team-a-*"
+
+  # start a Streamable HTTP server that exports the tool call and Kubernetes request spans to an OTLP collector
+  kubernetes-mcp-server --port 8080 --tracing-exporter otlp --tracing-endpoint http://localhost:4318
+
+  # start a STDIO server that appends the tool call and Kubernetes request spans to a file
+  kubernetes-mcp-server --tracing-exporter file --tracing-file /tmp/kubernetes-mcp-server-traces.json
+
+  # start a Streamable HTTP server that writes an audit log record for each tool call to a rotated file
+  kubernetes-mcp-server --port 8080 --audit-log-path /var/log/kubernetes-mcp-server/audit.log --audit-log-max-size 50
+
+  # start a server with the settings provided in a TOML or YAML configuration file (reloaded on change)
+  kubernetes-mcp-server --config /etc/kubernetes-mcp-server/config.toml
+
   # TODO: add more examples`,
 	Run: func(cmd *cobra.Command, args []string) {
-		initLogging()
+		configErr := initConfig()
+		loggingErr := initLogging()
 		if viper.GetBool("version") {
 			fmt.Println(version.Version)
 			return
 		}
-		mcpServer, err := mcp.NewSever()
+		if configErr != nil {
+			klog.Errorf("Failed to read configuration file: %s", configErr)
+			os.Exit(1)
+		}
+		if loggingErr != nil {
+			klog.Errorf("Failed to initialize logging: %s", loggingErr)
+			os.Exit(1)
+		}
+		shutdownTracing, err := initTracing(cmd.Context())
 		if err != nil {
-			panic(err)
+			klog.Errorf("Failed to initialize tracing: %s", err)
+			os.Exit(1)
+		}
+		defer func() { _ = shutdownTracing(context.Background()) }()
+		closeAuditLog, err := initAuditLog()
+		if err != nil {
+			klog.Errorf("Failed to initialize audit log: %s", err)
+			os.Exit(1)
+		}
+		defer func() { _ = closeAuditLog() }()
+		mcpServer, err := mcp.NewSever(mcpConfiguration())
+		if err != nil {
+			klog.Errorf("Failed to initialize MCP server: %s", err)
+			os.Exit(1)
 		}
 		defer mcpServer.Close()
+		if configFile := viper.GetString("config"); configFile != "" {
+			closeWatchConfig, err := config.Watch(configFile, func() error { return reloadConfig(mcpServer) })
+			if err != nil {
+				klog.Errorf("Failed to watch configuration file %s: %s", configFile, err)
+			} else {
+				defer func() { _ = closeWatchConfig() }()
+			}
+		}
 
-		var sseServer *server.SSEServer
-		if ssePort := viper.GetInt("sse-port"); ssePort > 0 {
-			sseServer = mcpServer.ServeSse(viper.GetString("sse-base-url"))
-			defer func() { _ = sseServer.Shutdown(cmd.Context()) }()
-			klog.V(0).Infof("SSE server starting on port %d", ssePort)
-			if err := sseServer.Start(fmt.Sprintf(":%d", ssePort)); err != nil {
-				klog.Errorf("Failed to start SSE server: %s", err)
-				return
+		if viper.GetInt("port") > 0 || viper.GetInt("sse-port") > 0 {
+			options, err := httpOptions(cmd.Context())
+			if err != nil {
+				klog.Errorf("Failed to initialize HTTP server: %s", err)
+				os.Exit(1)
+			}
+			if err = internalhttp.Serve(cmd.Context(), mcpServer, options); err != nil {
+				klog.Errorf("Failed to serve HTTP: %s", err)
+				os.Exit(1)
 			}
+			return
 		}
 		if err := mcpServer.ServeStdio(); err != nil && !errors.Is(err, context.Canceled) {
 			panic(err)
@@ -68,9 +150,35 @@ Kubernetes Model Context Protocol (MCP) server
 
 func init() {
 	rootCmd.Flags().BoolP("version", "v", false, "Print version information and quit")
+	rootCmd.Flags().StringP("config", "", "", "Path of the TOML or YAML configuration file (any of the flags can be provided as a configuration key), changes are applied without restarting the server")
+	rootCmd.Flags().StringP("kubeconfig", "", "", "Path to the kubeconfig file to use for authentication (defaults to KUBECONFIG or ~/.kube/config)")
 	rootCmd.Flags().IntP("log-level", "", 0, "Set the log level (from 0 to 9)")
+	rootCmd.Flags().StringP("log-format", "", "text", "Log format: text or json")
+	rootCmd.Flags().StringP("log-file", "", "", "Path of the file where the logs are appended (defaults to stderr in STDIO mode and stdout otherwise)")
+	rootCmd.Flags().IntP("port", "", 0, "Start a Streamable HTTP (/mcp) and SSE (/sse, /message) server on the specified port")
 	rootCmd.Flags().IntP("sse-port", "", 0, "Start a SSE server on the specified port")
 	rootCmd.Flags().StringP("sse-base-url", "", "", "SSE public base URL to use when sending the endpoint message (e.g. https://example.com)")
+	rootCmd.Flags().StringP("tls-cert-file", "", "", "Path to the TLS certificate file for the HTTP/SSE server (reloaded on change)")
+	rootCmd.Flags().StringP("tls-key-file", "", "", "Path to the TLS private key file for the HTTP/SSE server (reloaded on change)")
+	rootCmd.Flags().StringP("tls-client-ca-file", "", "", "Path to a CA bundle to verify the HTTP/SSE client certificates (mTLS), clients without a valid certificate are rejected")
+	rootCmd.Flags().StringP("auth-token-file", "", "", "Path to a CSV file with the static bearer tokens accepted by the HTTP/SSE server (token,user,uid,\"group1,group2\")")
+	rootCmd.Flags().StringP("oidc-issuer-url", "", "", "URL of the OIDC issuer whose JWTs are accepted as bearer tokens by the HTTP/SSE server")
+	rootCmd.Flags().StringP("oidc-audience", "", "", "Audience (aud claim) required in the OIDC JWTs (required with --oidc-issuer-url)")
+	rootCmd.Flags().StringP("oidc-jwks-file", "", "", "Path to a JWKS file with the OIDC issuer signing keys (instead of retrieving them from the issuer)")
+	rootCmd.Flags().StringP("oidc-username-prefix", "", "", "Prefix of the OIDC user names (sub claim), defaults to the issuer URL followed by # (- disables the prefix)")
+	rootCmd.Flags().StringP("oidc-groups-prefix", "", "", "Prefix of the OIDC user groups (groups claim), defaults to the issuer URL followed by # (- disables the prefix)")
+	rootCmd.Flags().StringP("identity-mode", "", "server", "Identity used for the Kubernetes requests of authenticated HTTP/SSE users: server (server credentials), impersonate (impersonate the user and groups), or passthrough (use the OIDC user bearer token)")
+	rootCmd.Flags().BoolP("read-only", "", false, "If true, only tools annotated with readOnlyHint=true are exposed and any write to the cluster is rejected")
+	rootCmd.Flags().StringSliceP("enabled-tools", "", []string{}, "Comma-separated list of tools to expose (by default all tools are exposed)")
+	rootCmd.Flags().StringSliceP("disabled-tools", "", []string{}, "Comma-separated list of tools to hide (applied after --enabled-tools)")
+	rootCmd.Flags().StringSliceP("denied-resources", "", []string{}, "Comma-separated list of resource kinds that can't be read or modified in \"<apiVersion> <kind>\" format, wildcards are supported (e.g. \"v1 Secret,rbac.authorization.k8s.io/* *\")")
+	rootCmd.Flags().StringSliceP("allowed-namespaces", "", []string{}, "Comma-separated list of namespace names or glob patterns that can be read or modified (e.g. \"team-a,team-a-*\"), by default all namespaces are allowed")
+	rootCmd.Flags().StringP("tracing-exporter", "", "", "OpenTelemetry span exporter: none, otlp, stdout, or file (defaults to the OTEL_TRACES_EXPORTER environment variable)")
+	rootCmd.Flags().StringP("tracing-endpoint", "", "", "OTLP/HTTP endpoint URL for the otlp tracing exporter (defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable)")
+	rootCmd.Flags().StringP("tracing-file", "", "", "Path of the file where the file tracing exporter appends the spans")
+	rootCmd.Flags().StringP("audit-log-path", "", "", "Path of the JSON-lines audit log file with a record for each tool call (\"-\" for stdout in HTTP/SSE modes), the audit log is disabled if empty")
+	rootCmd.Flags().IntP("audit-log-max-size", "", 100, "Maximum size in megabytes of the audit log file before it gets rotated")
+	rootCmd.Flags().IntP("audit-log-max-backups", "", 10, "Maximum number of rotated audit log files to retain (0 retains all of them)")
 	_ = viper.BindPFlags(rootCmd.Flags())
 }
 
@@ -80,12 +188,159 @@ func Execute() {
 	}
 }
 
-func initLogging() {
-	logger := textlogger.NewLogger(textlogger.NewConfig(textlogger.Output(os.Stdout)))
+func initConfig() error {
+	if configFile := viper.GetString("config"); configFile != "" {
+		return config.Read(configFile)
+	}
+	return nil
+}
+
+// reloadConfig is invoked whenever the configuration file changes, transport settings require a restart
+func reloadConfig(mcpServer *mcp.Server) error {
+	if err := initConfig(); err != nil {
+		klog.Errorf("Failed to reload configuration file: %s", err)
+		return err
+	}
+	if err := initLogging(); err != nil {
+		klog.Errorf("Failed to apply logging configuration changes: %s", err)
+		return err
+	}
+	if err := mcpServer.SetConfiguration(mcpConfiguration()); err != nil {
+		klog.Errorf("Failed to apply configuration file changes: %s", err)
+		return err
+	}
+	klog.V(1).Infof("Configuration reloaded from %s", viper.GetString("config"))
+	return nil
+}
+
+// httpOptions returns the HTTP server options, transport and authentication settings require a restart
+func httpOptions(ctx context.Context) (internalhttp.Options, error) {
+	options := internalhttp.Options{
+		Port:            viper.GetInt("port"),
+		SseBaseUrl:      viper.GetString("sse-base-url"),
+		TLSCertFile:     viper.GetString("tls-cert-file"),
+		TLSKeyFile:      viper.GetString("tls-key-file"),
+		TLSClientCAFile: viper.GetString("tls-client-ca-file"),
+	}
+	if options.Port == 0 {
+		options.Port = viper.GetInt("sse-port")
+		options.SseOnly = true
+	}
+	var authenticators auth.Authenticators
+	if tokenFile := viper.GetString("auth-token-file"); tokenFile != "" {
+		authenticator, err := auth.NewTokenFileAuthenticator(tokenFile)
+		if err != nil {
+			return options, err
+		}
+		authenticators = append(authenticators, authenticator)
+	}
+	if issuerUrl := viper.GetString("oidc-issuer-url"); issuerUrl != "" {
+		if viper.GetString("oidc-audience") == "" {
+			return options, errors.New("--oidc-audience is required with --oidc-issuer-url, the JWTs issued for other clients of the issuer must not be accepted")
+		}
+		authenticator, err := auth.NewOIDCAuthenticator(ctx, auth.OIDCOptions{
+			IssuerURL:      issuerUrl,
+			Audience:       viper.GetString("oidc-audience"),
+			JWKSFile:       viper.GetString("oidc-jwks-file"),
+			UsernamePrefix: viper.GetString("oidc-username-prefix"),
+			GroupsPrefix:   viper.GetString("oidc-groups-prefix"),
+		})
+		if err != nil {
+			return options, err
+		}
+		authenticators = append(authenticators, authenticator)
+	}
+	identityMode, _ := kubernetes.ParseIdentityMode(viper.GetString("identity-mode"))
+	if len(authenticators) > 0 {
+		options.Authenticator = authenticators
+	} else if identityMode != kubernetes.IdentityModeServer {
+		return options, fmt.Errorf("identity mode %s requires authentication, provide --auth-token-file or --oidc-issuer-url", identityMode)
+	}
+	// The static tokens are the server's own secrets, only the OIDC tokens can be passed through to the Kubernetes API server
+	if identityMode == kubernetes.IdentityModePassthrough && viper.GetString("oidc-issuer-url") == "" {
+		return options, fmt.Errorf("identity mode %s requires --oidc-issuer-url, the --auth-token-file tokens are not passed through", identityMode)
+	}
+	return options, nil
+}
+
+// initTracing sets up the OpenTelemetry exporter, tracing settings require a restart
+func initTracing(ctx context.Context) (func(context.Context) error, error) {
+	options := tracing.Options{
+		Exporter: viper.GetString("tracing-exporter"),
+		Endpoint: viper.GetString("tracing-endpoint"),
+		File:     viper.GetString("tracing-file"),
+	}
+	if options.Exporter == tracing.ExporterStdout && viper.GetInt("port") == 0 && viper.GetInt("sse-port") == 0 {
+		return nil, errors.New("stdout tracing exporter can't be used with the STDIO transport, use the file exporter instead")
+	}
+	return tracing.Init(ctx, options)
+}
+
+// initAuditLog enables the audit log, audit log settings require a restart
+func initAuditLog() (func() error, error) {
+	options := audit.Options{
+		Path:       viper.GetString("audit-log-path"),
+		MaxSize:    viper.GetInt("audit-log-max-size"),
+		MaxBackups: viper.GetInt("audit-log-max-backups"),
+	}
+	if options.Path == audit.PathStdout && viper.GetInt("port") == 0 && viper.GetInt("sse-port") == 0 {
+		return nil, errors.New("stdout audit log can't be used with the STDIO transport, provide a file path instead")
+	}
+	return audit.Init(options)
+}
+
+func mcpConfiguration() mcp.Configuration {
+	return mcp.Configuration{
+		Kubeconfig:        viper.GetString("kubeconfig"),
+		ReadOnly:          viper.GetBool("read-only"),
+		EnabledTools:      viper.GetStringSlice("enabled-tools"),
+		DisabledTools:     viper.GetStringSlice("disabled-tools"),
+		DeniedResources:   viper.GetStringSlice("denied-resources"),
+		AllowedNamespaces: viper.GetStringSlice("allowed-namespaces"),
+		IdentityMode:      viper.GetString("identity-mode"),
+	}
+}
+
+// logFile is the log file currently in use, closed when the logging configuration changes
+var logFile *os.File
+
+// initLogging configures the klog logger, logs are written to stderr in STDIO mode since stdout is used by the transport
+func initLogging() error {
+	logLevel := viper.GetInt("log-level")
+	var output io.Writer = os.Stdout
+	if viper.GetInt("port") == 0 && viper.GetInt("sse-port") == 0 {
+		output = os.Stderr
+	}
+	var file *os.File
+	if path := viper.GetString("log-file"); path != "" {
+		var err error
+		if file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		output = file
+	}
+	var logger logr.Logger
+	switch format := viper.GetString("log-format"); format {
+	case "", "text":
+		logger = textlogger.NewLogger(textlogger.NewConfig(textlogger.Output(output), textlogger.Verbosity(logLevel)))
+	case "json":
+		// logr verbosity levels are mapped to negative slog levels (V(1) is slog.Level(-1))
+		logger = logr.FromSlogHandler(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.Level(-logLevel)}))
+	default:
+		if file != nil {
+			_ = file.Close()
+		}
+		return fmt.Errorf("invalid log format %q, valid values are: text, json", format)
+	}
 	klog.SetLoggerWithOptions(logger)
+	if logFile != nil {
+		_ = logFile.Close()
+	}
+	logFile = file
 	flagSet := flag.NewFlagSet("kubernetes-mcp-server", flag.ContinueOnError)
 	klog.InitFlags(flagSet)
-	if logLevel := viper.GetInt("log-level"); logLevel >= 0 {
+	if logLevel >= 0 {
 		_ = flagSet.Parse([]string{"--v", strconv.Itoa(logLevel)})
 	}
+	return nil
 }
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"github.com/spf13/viper"
 	"io"
+	"k8s.io/klog/v2"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -27,3 +33,101 @@ func TestVersion(t *testing.T) {
 		return
 	}
 }
+
+func TestLoggingJsonFile(t *testing.T) {
+	defer viper.Reset()
+	logFilePath := filepath.Join(t.TempDir(), "kubernetes-mcp-server.log")
+	viper.Set("log-format", "json")
+	viper.Set("log-file", logFilePath)
+	viper.Set("log-level", 1)
+	err := initLogging()
+	klog.V(1).InfoS("Structured message", "key", "value")
+	klog.V(2).InfoS("Verbose message")
+	klog.Flush()
+	logs, _ := os.ReadFile(logFilePath)
+	t.Run("initLogging with json format succeeds", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("failed to initialize logging %v", err)
+		}
+	})
+	t.Run("initLogging with json format writes JSON lines to log file", func(t *testing.T) {
+		entry := map[string]any{}
+		if err := json.Unmarshal([]byte(strings.Split(string(logs), "\n")[0]), &entry); err != nil {
+			t.Fatalf("invalid JSON log %s: %v", logs, err)
+		}
+		if entry["msg"] != "Structured message" || entry["key"] != "value" {
+			t.Fatalf("invalid JSON log entry, got %v", entry)
+		}
+	})
+	t.Run("initLogging with json format honors log level", func(t *testing.T) {
+		if strings.Contains(string(logs), "Verbose message") {
+			t.Fatalf("expected verbose message to be discarded, got %s", logs)
+		}
+	})
+}
+
+func TestLoggingStdio(t *testing.T) {
+	defer viper.Reset()
+	originalErr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	err := initLogging()
+	klog.Info("Message in stderr")
+	klog.Flush()
+	_ = w.Close()
+	os.Stderr = originalErr
+	logs, _ := io.ReadAll(r)
+	t.Run("initLogging in STDIO mode writes to stderr", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("failed to initialize logging %v", err)
+		}
+		if !strings.Contains(string(logs), "Message in stderr") {
+			t.Fatalf("expected message in stderr, got %s", logs)
+		}
+	})
+}
+
+func TestLoggingInvalidFormat(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("log-format", "xml")
+	err := initLogging()
+	t.Run("initLogging with invalid format returns error", func(t *testing.T) {
+		if err == nil || err.Error() != "invalid log format \"xml\", valid values are: text, json" {
+			t.Fatalf("expected invalid log format error, got %v", err)
+		}
+	})
+}
+
+func TestHttpOptionsOIDCWithoutAudience(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("port", 8080)
+	viper.Set("oidc-issuer-url", "https://accounts.example.com")
+	_, err := httpOptions(context.Background())
+	t.Run("httpOptions with OIDC issuer and without audience returns error", func(t *testing.T) {
+		if err == nil || !strings.HasPrefix(err.Error(), "--oidc-audience is required with --oidc-issuer-url") {
+			t.Fatalf("expected missing audience error, got %v", err)
+		}
+	})
+}
+
+func TestHttpOptionsPassthroughWithTokenFile(t *testing.T) {
+	defer viper.Reset()
+	tokenFile := filepath.Join(t.TempDir(), "tokens.csv")
+	_ = os.WriteFile(tokenFile, []byte("token-alice,alice\n"), 0600)
+	viper.Set("port", 8080)
+	viper.Set("auth-token-file", tokenFile)
+	viper.Set("identity-mode", "passthrough")
+	_, err := httpOptions(context.Background())
+	t.Run("httpOptions with passthrough identity mode and only a token file returns error", func(t *testing.T) {
+		if err == nil || err.Error() != "identity mode passthrough requires --oidc-issuer-url, the --auth-token-file tokens are not passed through" {
+			t.Fatalf("expected passthrough error, got %v", err)
+		}
+	})
+	viper.Set("identity-mode", "impersonate")
+	_, err = httpOptions(context.Background())
+	t.Run("httpOptions with impersonate identity mode and a token file succeeds", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+}
@@ -1,15 +1,17 @@
 package kubernetes
 
 import (
+	"fmt"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/tools/clientcmd/api/latest"
+	"slices"
 )
 
-func ConfigurationView(minify bool) (string, error) {
+func (k *Kubernetes) ConfigurationView(minify bool) (string, error) {
 	var cfg clientcmdapi.Config
 	var err error
 	inClusterConfig, err := InClusterConfig()
-	if err == nil && inClusterConfig != nil {
+	if k.options.Kubeconfig == "" && err == nil && inClusterConfig != nil {
 		cfg = *clientcmdapi.NewConfig()
 		cfg.Clusters["cluster"] = &clientcmdapi.Cluster{
 			Server:                inClusterConfig.Host,
@@ -23,7 +25,7 @@ func ConfigurationView(minify bool) (string, error) {
 			AuthInfo: "user",
 		}
 		cfg.CurrentContext = "context"
-	} else if cfg, err = resolveConfig().RawConfig(); err != nil {
+	} else if cfg, err = resolveConfig(k.options.Kubeconfig, "").RawConfig(); err != nil {
 		return "", err
 	}
 	if minify {
@@ -41,3 +43,42 @@ func ConfigurationView(minify bool) (string, error) {
 	}
 	return marshal(convertedObj)
 }
+
+func (k *Kubernetes) ContextsList() (string, error) {
+	cfg, err := resolveConfig(k.options.Kubeconfig, "").RawConfig()
+	if err != nil {
+		return "", err
+	}
+	if len(cfg.Contexts) == 0 {
+		return "No contexts found in the kubeconfig", nil
+	}
+	contextNames := make([]string, 0, len(cfg.Contexts))
+	for name := range cfg.Contexts {
+		contextNames = append(contextNames, name)
+	}
+	slices.Sort(contextNames)
+	var contexts []map[string]any
+	for _, name := range contextNames {
+		kubeContext := cfg.Contexts[name]
+		namespace := kubeContext.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		server := ""
+		if cluster, ok := cfg.Clusters[kubeContext.Cluster]; ok {
+			server = cluster.Server
+		}
+		contexts = append(contexts, map[string]any{
+			"name":      name,
+			"cluster":   kubeContext.Cluster,
+			"server":    server,
+			"namespace": namespace,
+			"current":   name == cfg.CurrentContext,
+		})
+	}
+	yamlContexts, err := marshal(contexts)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("The following contexts (YAML format) were found in the kubeconfig:\n%s", yamlContexts), nil
+}
@@ -0,0 +1,74 @@
+package kubernetes
+
+import (
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/auth"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"strings"
+)
+
+// IdentityMode defines which identity is used to perform the requests to the Kubernetes API server
+type IdentityMode string
+
+const (
+	// IdentityModeServer performs every request with the server's own credentials (default)
+	IdentityModeServer IdentityMode = "server"
+	// IdentityModeImpersonate impersonates the authenticated user (and groups) using the server's credentials
+	IdentityModeImpersonate IdentityMode = "impersonate"
+	// IdentityModePassthrough performs the requests with the bearer token provided by the authenticated user
+	IdentityModePassthrough IdentityMode = "passthrough"
+)
+
+var identityModes = []IdentityMode{IdentityModeServer, IdentityModeImpersonate, IdentityModePassthrough}
+
+// ParseIdentityMode validates the provided identity mode, an empty value resolves to IdentityModeServer
+func ParseIdentityMode(mode string) (IdentityMode, error) {
+	if mode == "" {
+		return IdentityModeServer, nil
+	}
+	for _, identityMode := range identityModes {
+		if strings.EqualFold(mode, string(identityMode)) {
+			return identityMode, nil
+		}
+	}
+	return "", fmt.Errorf("invalid identity mode %q, valid values are: %s, %s, %s",
+		mode, IdentityModeServer, IdentityModeImpersonate, IdentityModePassthrough)
+}
+
+// Derived returns a Kubernetes client that performs the requests on behalf of the provided authenticated user,
+// so that the Kubernetes RBAC decides what the user can do.
+// The discovery information is shared with the parent client.
+// The parent client is returned if there's no authenticated user or if the identity mode is IdentityModeServer.
+func (k *Kubernetes) Derived(user *auth.User) (*Kubernetes, error) {
+	if user == nil {
+		return k, nil
+	}
+	var cfg *rest.Config
+	switch k.options.IdentityMode {
+	case IdentityModeImpersonate:
+		cfg = rest.CopyConfig(k.cfg)
+		cfg.Impersonate = rest.ImpersonationConfig{UserName: user.Name, Groups: user.Groups}
+	case IdentityModePassthrough:
+		if !user.Passthrough {
+			return nil, fmt.Errorf("the token of user %s can't be passed through to the Kubernetes API server, only the tokens issued by the OIDC provider can", user.Name)
+		}
+		// Remove any server credentials (client certificates, tokens, auth providers...) but keep the transport wrappers
+		cfg = rest.AnonymousClientConfig(k.cfg)
+		cfg.WrapTransport = k.cfg.WrapTransport
+		cfg.BearerToken = user.Token
+	default:
+		return k, nil
+	}
+	derived := *k
+	derived.cfg = cfg
+	var err error
+	if derived.clientSet, err = kubernetes.NewForConfig(cfg); err != nil {
+		return nil, err
+	}
+	if derived.dynamicClient, err = dynamic.NewForConfig(cfg); err != nil {
+		return nil, err
+	}
+	return &derived, nil
+}
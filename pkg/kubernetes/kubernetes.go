@@ -1,7 +1,7 @@
 package kubernetes
 
 import (
-	"github.com/fsnotify/fsnotify"
+	"context"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -13,6 +13,7 @@ import (
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"net/http"
 	"sigs.k8s.io/yaml"
 )
 
@@ -30,10 +31,24 @@ var InClusterConfig = func() (*rest.Config, error) {
 
 type CloseWatchKubeConfig func() error
 
+type Options struct {
+	// Kubeconfig is the path to the kubeconfig file, if empty KUBECONFIG or the default location (~/.kube/config) are used
+	Kubeconfig string
+	// ReadOnly prevents any request that might modify the cluster
+	ReadOnly bool
+	// DeniedResources are the resource kinds that can't be read or modified
+	DeniedResources []DeniedResource
+	// AllowedNamespaces are the namespaces that can be read or modified (all namespaces if empty)
+	AllowedNamespaces []AllowedNamespace
+	// IdentityMode defines which identity (server, impersonated user, or user token) is used for the requests
+	IdentityMode IdentityMode
+}
+
 type Kubernetes struct {
 	cfg                         *rest.Config
-	kubeConfigFiles             []string
-	CloseWatchKubeConfig        CloseWatchKubeConfig
+	options                     Options
+	clientCmdConfig             clientcmd.ClientConfig
+	kubeConfigSnapshot          *clientcmdapi.Config
 	scheme                      *runtime.Scheme
 	parameterCodec              runtime.ParameterCodec
 	clientSet                   kubernetes.Interface
@@ -42,14 +57,26 @@ type Kubernetes struct {
 	dynamicClient               *dynamic.DynamicClient
 }
 
-func NewKubernetes() (*Kubernetes, error) {
-	k8s := &Kubernetes{}
+// newKubernetes creates a Kubernetes client for the provided kubeconfig context.
+// An empty context resolves to the in-cluster configuration or the kubeconfig current-context.
+// Read-only clients reject any request that might modify the cluster, every request is traced (nested in the span of its context).
+func newKubernetes(options Options, kubeContext string) (*Kubernetes, error) {
+	k8s := &Kubernetes{options: options}
 	var err error
-	k8s.cfg, err = resolveClientConfig()
+	k8s.clientCmdConfig = resolveConfig(options.Kubeconfig, kubeContext)
+	k8s.cfg, err = resolveClientConfig(options.Kubeconfig, kubeContext)
 	if err != nil {
 		return nil, err
 	}
-	k8s.kubeConfigFiles = resolveConfig().ConfigAccess().GetLoadingPrecedence()
+	if options.ReadOnly {
+		k8s.cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &readOnlyRoundTripper{delegate: rt}
+		})
+	}
+	k8s.cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &tracingRoundTripper{delegate: rt}
+	})
+	k8s.kubeConfigSnapshot = kubeConfigSnapshot(options.Kubeconfig, kubeContext)
 	k8s.clientSet, err = kubernetes.NewForConfig(k8s.cfg)
 	if err != nil {
 		return nil, err
@@ -71,44 +98,6 @@ func NewKubernetes() (*Kubernetes, error) {
 	return k8s, nil
 }
 
-func (k *Kubernetes) WatchKubeConfig(onKubeConfigChange func() error) {
-	if len(k.kubeConfigFiles) == 0 {
-		return
-	}
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return
-	}
-	for _, file := range k.kubeConfigFiles {
-		_ = watcher.Add(file)
-	}
-	go func() {
-		for {
-			select {
-			case _, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				_ = onKubeConfigChange()
-			case _, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
-			}
-		}
-	}()
-	if k.CloseWatchKubeConfig != nil {
-		_ = k.CloseWatchKubeConfig()
-	}
-	k.CloseWatchKubeConfig = watcher.Close
-}
-
-func (k *Kubernetes) Close() {
-	if k.CloseWatchKubeConfig != nil {
-		_ = k.CloseWatchKubeConfig()
-	}
-}
-
 func marshal(v any) (string, error) {
 	switch t := v.(type) {
 	case []unstructured.Unstructured:
@@ -131,35 +120,71 @@ func marshal(v any) (string, error) {
 	return string(ret), nil
 }
 
-func resolveConfig() clientcmd.ClientConfig {
-	pathOptions := clientcmd.NewDefaultPathOptions()
+func resolveConfig(kubeconfig, kubeContext string) clientcmd.ClientConfig {
+	if kubeconfig == "" {
+		kubeconfig = clientcmd.NewDefaultPathOptions().GetDefaultFilename()
+	}
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: pathOptions.GetDefaultFilename()},
-		&clientcmd.ConfigOverrides{ClusterInfo: clientcmdapi.Cluster{Server: ""}})
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{ClusterInfo: clientcmdapi.Cluster{Server: ""}, CurrentContext: kubeContext})
 }
 
-func resolveClientConfig() (*rest.Config, error) {
-	inClusterConfig, err := InClusterConfig()
-	if err == nil && inClusterConfig != nil {
-		return inClusterConfig, nil
+func resolveClientConfig(kubeconfig, kubeContext string) (*rest.Config, error) {
+	// In-cluster configuration only applies when no explicit kubeconfig or context are requested
+	if kubeconfig == "" && kubeContext == "" {
+		inClusterConfig, err := InClusterConfig()
+		if err == nil && inClusterConfig != nil {
+			return inClusterConfig, nil
+		}
 	}
-	cfg, err := resolveConfig().ClientConfig()
+	cfg, err := resolveConfig(kubeconfig, kubeContext).ClientConfig()
 	if cfg != nil && cfg.UserAgent == "" {
 		cfg.UserAgent = rest.DefaultKubernetesUserAgent()
 	}
 	return cfg, err
 }
 
-func configuredNamespace() string {
-	if ns, _, nsErr := resolveConfig().Namespace(); nsErr == nil {
+// kubeConfigSnapshot returns the minified kubeconfig (context, cluster and user) for the provided context.
+// Used to detect whether a kubeconfig change affects a given client.
+func kubeConfigSnapshot(kubeconfig, kubeContext string) *clientcmdapi.Config {
+	cfg, err := resolveConfig(kubeconfig, kubeContext).RawConfig()
+	if err != nil {
+		return nil
+	}
+	if kubeContext != "" {
+		cfg.CurrentContext = kubeContext
+	}
+	if err = clientcmdapi.MinifyConfig(&cfg); err != nil {
+		return nil
+	}
+	return &cfg
+}
+
+func (k *Kubernetes) configuredNamespace() string {
+	if ns, _, nsErr := k.clientCmdConfig.Namespace(); nsErr == nil {
 		return ns
 	}
 	return ""
 }
 
-func namespaceOrDefault(namespace string) string {
+// namespaceOrDefault returns the provided namespace or the configured one if empty.
+// An ErrNamespaceDenied error is returned if the resulting namespace is not allowed by the configuration.
+func (k *Kubernetes) namespaceOrDefault(namespace string) (string, error) {
 	if namespace == "" {
-		return configuredNamespace()
+		namespace = k.configuredNamespace()
 	}
-	return namespace
+	if err := k.checkNamespaceAllowed(namespace); err != nil {
+		return "", err
+	}
+	return namespace, nil
+}
+
+// Host returns the URL of the Kubernetes API server
+func (k *Kubernetes) Host() string {
+	return k.cfg.Host
+}
+
+// Ping checks that the Kubernetes API server is reachable through the discovery client
+func (k *Kubernetes) Ping(ctx context.Context) error {
+	return k.discoveryClient.RESTClient().Get().AbsPath("/version").Do(ctx).Error()
 }
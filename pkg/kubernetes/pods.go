@@ -1,9 +1,8 @@
 package kubernetes
 
 import (
-	"bytes"
 	"context"
-	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
 	"github.com/manusa/kubernetes-mcp-server/pkg/version"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -11,10 +10,8 @@ import (
 	labelutil "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/rand"
-	"k8s.io/client-go/tools/remotecommand"
 )
 
 func (k *Kubernetes) PodsListInAllNamespaces(ctx context.Context) (string, error) {
@@ -30,13 +27,26 @@ func (k *Kubernetes) PodsListInNamespace(ctx context.Context, namespace string)
 }
 
 func (k *Kubernetes) PodsGet(ctx context.Context, namespace, name string) (string, error) {
+	namespace, err := k.namespaceOrDefault(namespace)
+	if err != nil {
+		return "", err
+	}
 	return k.ResourcesGet(ctx, &schema.GroupVersionKind{
 		Group: "", Version: "v1", Kind: "Pod",
-	}, namespaceOrDefault(namespace), name)
+	}, namespace, name)
 }
 
 func (k *Kubernetes) PodsDelete(ctx context.Context, namespace, name string) (string, error) {
-	namespace = namespaceOrDefault(namespace)
+	if err := k.checkWritable(); err != nil {
+		return "", err
+	}
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}); err != nil {
+		return "", err
+	}
+	namespace, err := k.namespaceOrDefault(namespace)
+	if err != nil {
+		return "", err
+	}
 	pod, err := k.clientSet.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return "", err
@@ -49,18 +59,21 @@ func (k *Kubernetes) PodsDelete(ctx context.Context, namespace, name string) (st
 	}.AsSelector()
 
 	// Delete managed service
-	if isManaged {
+	if isManaged && k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"}) == nil {
 		if sl, _ := k.clientSet.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{
 			LabelSelector: managedLabelSelector.String(),
 		}); sl != nil {
 			for _, svc := range sl.Items {
-				_ = k.clientSet.CoreV1().Services(namespace).Delete(ctx, svc.Name, metav1.DeleteOptions{})
+				if k.clientSet.CoreV1().Services(namespace).Delete(ctx, svc.Name, metav1.DeleteOptions{}) == nil {
+					audit.AddObject(ctx, audit.ActionDelete, v1.SchemeGroupVersion.WithKind("Service"), &svc)
+				}
 			}
 		}
 	}
 
 	// Delete managed Route
-	if isManaged && k.supportsGroupVersion("route.openshift.io/v1") {
+	if isManaged && k.supportsGroupVersion("route.openshift.io/v1") &&
+		k.checkResourceAllowed(&schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}) == nil {
 		routeResources := k.dynamicClient.
 			Resource(schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}).
 			Namespace(namespace)
@@ -68,29 +81,18 @@ func (k *Kubernetes) PodsDelete(ctx context.Context, namespace, name string) (st
 			LabelSelector: managedLabelSelector.String(),
 		}); rl != nil {
 			for _, route := range rl.Items {
-				_ = routeResources.Delete(ctx, route.GetName(), metav1.DeleteOptions{})
+				if routeResources.Delete(ctx, route.GetName(), metav1.DeleteOptions{}) == nil {
+					audit.AddObject(ctx, audit.ActionDelete, route.GroupVersionKind(), &route)
+				}
 			}
 		}
 
 	}
-	return "Pod deleted successfully",
-		k.clientSet.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})
-}
-
-func (k *Kubernetes) PodsLog(ctx context.Context, namespace, name string) (string, error) {
-	tailLines := int64(256)
-	req := k.clientSet.CoreV1().Pods(namespaceOrDefault(namespace)).GetLogs(name, &v1.PodLogOptions{
-		TailLines: &tailLines,
-	})
-	res := req.Do(ctx)
-	if res.Error() != nil {
-		return "", res.Error()
-	}
-	rawData, err := res.Raw()
-	if err != nil {
+	if err = k.clientSet.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
 		return "", err
 	}
-	return string(rawData), nil
+	audit.AddObject(ctx, audit.ActionDelete, v1.SchemeGroupVersion.WithKind("Pod"), pod)
+	return "Pod deleted successfully", nil
 }
 
 func (k *Kubernetes) PodsRun(ctx context.Context, namespace, name, image string, port int32) (string, error) {
@@ -103,11 +105,15 @@ func (k *Kubernetes) PodsRun(ctx context.Context, namespace, name, image string,
 		AppKubernetesManagedBy: version.BinaryName,
 		AppKubernetesPartOf:    version.BinaryName + "-run-sandbox",
 	}
+	namespace, err := k.namespaceOrDefault(namespace)
+	if err != nil {
+		return "", err
+	}
 	// NewPod
 	var resources []any
 	pod := &v1.Pod{
 		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
-		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespaceOrDefault(namespace), Labels: labels},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
 		Spec: v1.PodSpec{Containers: []v1.Container{{
 			Name:            name,
 			Image:           image,
@@ -119,7 +125,7 @@ func (k *Kubernetes) PodsRun(ctx context.Context, namespace, name, image string,
 		pod.Spec.Containers[0].Ports = []v1.ContainerPort{{ContainerPort: port}}
 		resources = append(resources, &v1.Service{
 			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
-			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespaceOrDefault(namespace), Labels: labels},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
 			Spec: v1.ServiceSpec{
 				Selector: labels,
 				Type:     v1.ServiceTypeClusterIP,
@@ -134,7 +140,7 @@ func (k *Kubernetes) PodsRun(ctx context.Context, namespace, name, image string,
 				"kind":       "Route",
 				"metadata": map[string]interface{}{
 					"name":      name,
-					"namespace": namespaceOrDefault(namespace),
+					"namespace": namespace,
 					"labels":    labels,
 				},
 				"spec": map[string]interface{}{
@@ -172,64 +178,3 @@ func (k *Kubernetes) PodsRun(ctx context.Context, namespace, name, image string,
 	}
 	return k.resourcesCreateOrUpdate(ctx, toCreate)
 }
-
-func (k *Kubernetes) PodsExec(ctx context.Context, namespace, name, container string, command []string) (string, error) {
-	namespace = namespaceOrDefault(namespace)
-	pod, err := k.clientSet.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return "", err
-	}
-	// https://github.com/kubernetes/kubectl/blob/5366de04e168bcbc11f5e340d131a9ca8b7d0df4/pkg/cmd/exec/exec.go#L350-L352
-	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
-		return "", fmt.Errorf("cannot exec into a container in a completed pod; current phase is %s", pod.Status.Phase)
-	}
-	podExecOptions := &v1.PodExecOptions{
-		Command: command,
-		Stdout:  true,
-		Stderr:  true,
-	}
-	executor, err := k.createExecutor(namespace, name, podExecOptions)
-	if err != nil {
-		return "", err
-	}
-	if container == "" {
-		container = pod.Spec.Containers[0].Name
-	}
-	stdout := bytes.NewBuffer(make([]byte, 0))
-	stderr := bytes.NewBuffer(make([]byte, 0))
-	if err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
-		Stdout: stdout, Stderr: stderr, Tty: false,
-	}); err != nil {
-		return "", err
-	}
-	if stdout.Len() > 0 {
-		return stdout.String(), nil
-	}
-	if stderr.Len() > 0 {
-		return stderr.String(), nil
-	}
-	return "", nil
-}
-
-func (k *Kubernetes) createExecutor(namespace, name string, podExecOptions *v1.PodExecOptions) (remotecommand.Executor, error) {
-	// Compute URL
-	// https://github.com/kubernetes/kubectl/blob/5366de04e168bcbc11f5e340d131a9ca8b7d0df4/pkg/cmd/exec/exec.go#L382-L397
-	req := k.clientSet.CoreV1().RESTClient().
-		Post().
-		Resource("pods").
-		Namespace(namespace).
-		Name(name).
-		SubResource("exec")
-	req.VersionedParams(podExecOptions, k.parameterCodec)
-	spdyExec, err := remotecommand.NewSPDYExecutor(k.cfg, "POST", req.URL())
-	if err != nil {
-		return nil, err
-	}
-	webSocketExec, err := remotecommand.NewWebSocketExecutor(k.cfg, "GET", req.URL().String())
-	if err != nil {
-		return nil, err
-	}
-	return remotecommand.NewFallbackExecutor(webSocketExec, spdyExec, func(err error) bool {
-		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
-	})
-}
@@ -0,0 +1,189 @@
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/exec"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// PodsCpMaxBytes is the maximum size of the files copied from or to a Pod container,
+	// the content of the files is exchanged with the model
+	PodsCpMaxBytes = 1024 * 1024
+
+	EncodingText   = "text"
+	EncodingBase64 = "base64"
+)
+
+// PodsCpOptions are the options to copy files from or to a Pod container
+type PodsCpOptions struct {
+	// Container to copy the file from or to, defaults to the kubectl.kubernetes.io/default-container annotation or the first container
+	Container string
+	// Timeout is the maximum duration of the copy (PodsExecDefaultTimeout if 0)
+	Timeout time.Duration
+}
+
+// PodsCpFromResult is a file copied from a Pod container, binary content is base64 encoded
+type PodsCpFromResult struct {
+	Container string `json:"container"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Encoding  string `json:"encoding"`
+	Content   string `json:"content"`
+}
+
+// PodsCpFrom copies a file from the Pod container through a tar stream of the exec subresource (as kubectl cp),
+// the container image must provide the tar binary
+func (k *Kubernetes) PodsCpFrom(ctx context.Context, namespace, name, filePath string, options PodsCpOptions) (string, error) {
+	if err := k.checkWritable(); err != nil {
+		return "", err
+	}
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}); err != nil {
+		return "", err
+	}
+	dir, base, err := splitCpPath(filePath)
+	if err != nil {
+		return "", err
+	}
+	timeout, err := execTimeout(options.Timeout)
+	if err != nil {
+		return "", err
+	}
+	namespace, container, err := k.execTarget(ctx, namespace, name, options.Container)
+	if err != nil {
+		return "", err
+	}
+	reader, writer := io.Pipe()
+	stderr := bytes.NewBuffer(make([]byte, 0))
+	streamErr := make(chan error, 1)
+	go func() {
+		err := k.streamInContainer(ctx, namespace, name, container, []string{"tar", "cf", "-", "-C", dir, base}, nil, writer, stderr, timeout)
+		_ = writer.CloseWithError(err)
+		streamErr <- err
+	}()
+	content, readErr := readTarFile(reader, filePath, PodsCpMaxBytes)
+	if readErr == nil {
+		// Drain the end of the archive so that tar completes
+		_, _ = io.Copy(io.Discard, reader)
+	}
+	// Closing the reader aborts the copy if the file was rejected before the end of the stream
+	_ = reader.Close()
+	if err = <-streamErr; err != nil {
+		var exitErr exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("failed to read %s: %s", filePath, strings.TrimSpace(stderr.String()))
+		} else if readErr == nil || errors.Is(readErr, io.EOF) {
+			return "", err
+		}
+	}
+	if readErr != nil {
+		return "", readErr
+	}
+	result := &PodsCpFromResult{Container: container, Path: filePath, Size: int64(len(content))}
+	if utf8.Valid(content) && !bytes.ContainsRune(content, 0) {
+		result.Encoding = EncodingText
+		result.Content = string(content)
+	} else {
+		result.Encoding = EncodingBase64
+		result.Content = base64.StdEncoding.EncodeToString(content)
+	}
+	return marshal(result)
+}
+
+// PodsCpTo copies the content to a file in the Pod container through a tar stream of the exec subresource (as kubectl cp),
+// the container image must provide the tar binary
+func (k *Kubernetes) PodsCpTo(ctx context.Context, namespace, name, filePath string, content []byte, options PodsCpOptions) (string, error) {
+	if err := k.checkWritable(); err != nil {
+		return "", err
+	}
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}); err != nil {
+		return "", err
+	}
+	if len(content) > PodsCpMaxBytes {
+		return "", fmt.Errorf("content size %d bytes exceeds the maximum of %d bytes", len(content), PodsCpMaxBytes)
+	}
+	dir, base, err := splitCpPath(filePath)
+	if err != nil {
+		return "", err
+	}
+	timeout, err := execTimeout(options.Timeout)
+	if err != nil {
+		return "", err
+	}
+	namespace, container, err := k.execTarget(ctx, namespace, name, options.Container)
+	if err != nil {
+		return "", err
+	}
+	archive := bytes.NewBuffer(make([]byte, 0, len(content)+2048))
+	tarWriter := tar.NewWriter(archive)
+	if err = tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     base,
+		Mode:     0644,
+		Size:     int64(len(content)),
+		ModTime:  time.Now(),
+	}); err != nil {
+		return "", err
+	}
+	if _, err = tarWriter.Write(content); err != nil {
+		return "", err
+	}
+	if err = tarWriter.Close(); err != nil {
+		return "", err
+	}
+	stdout := bytes.NewBuffer(make([]byte, 0))
+	stderr := bytes.NewBuffer(make([]byte, 0))
+	err = k.streamInContainer(ctx, namespace, name, container, []string{"tar", "xmf", "-", "-C", dir}, archive, stdout, stderr, timeout)
+	var exitErr exec.ExitError
+	if errors.As(err, &exitErr) {
+		return "", fmt.Errorf("failed to write %s: %s", filePath, strings.TrimSpace(stderr.String()))
+	} else if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("File %s (%d bytes) copied to container %s of pod %s in namespace %s", filePath, len(content), container, name, namespace), nil
+}
+
+// readTarFile reads the content of the only file of the tar stream, files larger than maxBytes are rejected
+func readTarFile(stream io.Reader, filePath string, maxBytes int64) ([]byte, error) {
+	tarReader := tar.NewReader(stream)
+	header, err := tarReader.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	switch header.Typeflag {
+	case tar.TypeReg:
+	case tar.TypeDir:
+		return nil, fmt.Errorf("%s is a directory, only files can be copied", filePath)
+	case tar.TypeSymlink:
+		return nil, fmt.Errorf("%s is a symbolic link to %s, copy the target file instead", filePath, header.Linkname)
+	default:
+		return nil, fmt.Errorf("%s is not a regular file", filePath)
+	}
+	if header.Size > maxBytes {
+		return nil, fmt.Errorf("%s size %d bytes exceeds the maximum of %d bytes", filePath, header.Size, maxBytes)
+	}
+	content, err := io.ReadAll(tarReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	return content, nil
+}
+
+// splitCpPath splits the path of a file in a container into its directory and file name
+func splitCpPath(filePath string) (string, string, error) {
+	base := path.Base(filePath)
+	if filePath == "" || base == "." || base == ".." || base == "/" {
+		return "", "", fmt.Errorf("invalid file path %q", filePath)
+	}
+	return path.Dir(filePath), base, nil
+}
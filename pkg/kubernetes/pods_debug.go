@@ -0,0 +1,127 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"strings"
+	"time"
+)
+
+const (
+	// PodsDebugDefaultImage is the image of the ephemeral debug container if none is provided
+	PodsDebugDefaultImage = "busybox"
+	// PodsDebugRunningTimeout is the maximum time to wait for the ephemeral debug container to be running
+	PodsDebugRunningTimeout = 2 * time.Minute
+	// podsDebugPollInterval is the interval between the checks of the ephemeral debug container status
+	podsDebugPollInterval = time.Second
+)
+
+// PodsDebugOptions are the options to run a command in an ephemeral debug container
+type PodsDebugOptions struct {
+	// Image of the ephemeral debug container (PodsDebugDefaultImage if empty)
+	Image string
+	// Target container whose process namespace is shared with the ephemeral debug container (none if empty)
+	Target string
+	// Timeout is the maximum duration of the command execution (PodsExecDefaultTimeout if 0)
+	Timeout time.Duration
+}
+
+// PodsDebug adds an ephemeral debug container to the Pod, waits for it to be running, and executes the command in it
+// (the equivalent of kubectl debug -it --target).
+// Ephemeral containers can't be removed from a Pod, the debug container keeps running until the Pod is deleted.
+func (k *Kubernetes) PodsDebug(ctx context.Context, namespace, name string, command []string, options PodsDebugOptions) (string, error) {
+	if err := k.checkWritable(); err != nil {
+		return "", err
+	}
+	gvk := &schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}
+	if err := k.checkResourceAllowed(gvk); err != nil {
+		return "", err
+	}
+	timeout, err := execTimeout(options.Timeout)
+	if err != nil {
+		return "", err
+	}
+	namespace, err = k.namespaceOrDefault(namespace)
+	if err != nil {
+		return "", err
+	}
+	pod, err := k.execPod(ctx, namespace, name)
+	if err != nil {
+		return "", err
+	}
+	if options.Target != "" {
+		if _, err = podContainer(pod, options.Target); err != nil {
+			return "", fmt.Errorf("invalid target: %w", err)
+		}
+	}
+	image := options.Image
+	if image == "" {
+		image = PodsDebugDefaultImage
+	}
+	// The debug container keeps its standard input open (as kubectl debug -i) so that the default shell of the image keeps running
+	container := "debugger-" + rand.String(5)
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, v1.EphemeralContainer{
+		EphemeralContainerCommon: v1.EphemeralContainerCommon{
+			Name:                     container,
+			Image:                    image,
+			ImagePullPolicy:          v1.PullIfNotPresent,
+			Stdin:                    true,
+			TerminationMessagePolicy: v1.TerminationMessageReadFile,
+		},
+		TargetContainerName: options.Target,
+	})
+	updated, err := k.clientSet.CoreV1().Pods(namespace).UpdateEphemeralContainers(ctx, name, pod, metav1.UpdateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to add ephemeral debug container: %w", err)
+	}
+	audit.AddObject(ctx, audit.ActionApply, *gvk, updated)
+	if err = k.waitForEphemeralContainer(ctx, namespace, name, container); err != nil {
+		return "", err
+	}
+	result, err := k.execInContainer(ctx, namespace, name, container, command, "", timeout)
+	if err != nil {
+		return "", err
+	}
+	return marshal(result)
+}
+
+// waitForEphemeralContainer waits until the ephemeral container is running, or fails if it terminates or can't be started
+func (k *Kubernetes) waitForEphemeralContainer(ctx context.Context, namespace, name, container string) error {
+	lastState := "no status reported"
+	err := wait.PollUntilContextTimeout(ctx, podsDebugPollInterval, PodsDebugRunningTimeout, true, func(ctx context.Context) (bool, error) {
+		pod, err := k.clientSet.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != container {
+				continue
+			}
+			switch {
+			case status.State.Running != nil:
+				return true, nil
+			case status.State.Terminated != nil:
+				return false, fmt.Errorf("ephemeral debug container %s terminated: %s %s",
+					container, status.State.Terminated.Reason, status.State.Terminated.Message)
+			case status.State.Waiting != nil:
+				lastState = strings.TrimSpace(status.State.Waiting.Reason + " " + status.State.Waiting.Message)
+				switch status.State.Waiting.Reason {
+				case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerError", "CreateContainerConfigError":
+					return false, fmt.Errorf("ephemeral debug container %s can't be started: %s", container, lastState)
+				}
+			}
+		}
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out waiting for ephemeral debug container %s to be running (%s): %w", container, lastState, err)
+	}
+	return err
+}
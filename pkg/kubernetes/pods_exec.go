@@ -0,0 +1,244 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// PodsExecDefaultTimeout is the maximum duration of a command execution if no timeout is provided
+	PodsExecDefaultTimeout = time.Minute
+	// PodsExecMaxTimeout is the upper limit of the command execution timeout
+	PodsExecMaxTimeout = 10 * time.Minute
+	// PodsExecMaxOutputBytes is the limit of the returned stdout and stderr (each) to protect the context window of the model,
+	// the first bytes are kept
+	PodsExecMaxOutputBytes = PodsLogMaxBytes
+	// defaultContainerAnnotation is the annotation kubectl uses to select the container of a Pod when none is provided
+	defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+)
+
+// PodsExecOptions are the options to execute a command in a Pod container
+type PodsExecOptions struct {
+	// Container to execute the command in, defaults to the kubectl.kubernetes.io/default-container annotation or the first container
+	Container string
+	// Stdin is passed to the standard input of the command (no standard input if empty)
+	Stdin string
+	// Timeout is the maximum duration of the command execution (PodsExecDefaultTimeout if 0)
+	Timeout time.Duration
+}
+
+// PodsExecResult is the outcome of a command executed in a Pod container
+type PodsExecResult struct {
+	Container string `json:"container"`
+	ExitCode  int    `json:"exitCode"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	// StdoutTruncated is true if the stdout exceeds PodsExecMaxOutputBytes and only its first bytes are returned
+	StdoutTruncated bool `json:"stdoutTruncated,omitempty"`
+	// StderrTruncated is true if the stderr exceeds PodsExecMaxOutputBytes and only its first bytes are returned
+	StderrTruncated bool `json:"stderrTruncated,omitempty"`
+}
+
+func (k *Kubernetes) PodsExec(ctx context.Context, namespace, name string, command []string, options PodsExecOptions) (string, error) {
+	if err := k.checkWritable(); err != nil {
+		return "", err
+	}
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}); err != nil {
+		return "", err
+	}
+	timeout, err := execTimeout(options.Timeout)
+	if err != nil {
+		return "", err
+	}
+	namespace, container, err := k.execTarget(ctx, namespace, name, options.Container)
+	if err != nil {
+		return "", err
+	}
+	result, err := k.execInContainer(ctx, namespace, name, container, command, options.Stdin, timeout)
+	if err != nil {
+		return "", err
+	}
+	return marshal(result)
+}
+
+// execTarget resolves the namespace and container to execute a command in after validating the Pod is running
+func (k *Kubernetes) execTarget(ctx context.Context, namespace, name, container string) (string, string, error) {
+	namespace, err := k.namespaceOrDefault(namespace)
+	if err != nil {
+		return "", "", err
+	}
+	pod, err := k.execPod(ctx, namespace, name)
+	if err != nil {
+		return "", "", err
+	}
+	container, err = podContainer(pod, container)
+	if err != nil {
+		return "", "", err
+	}
+	return namespace, container, nil
+}
+
+// execPod retrieves the Pod to execute a command in, it fails if the Pod is completed
+func (k *Kubernetes) execPod(ctx context.Context, namespace, name string) (*v1.Pod, error) {
+	pod, err := k.clientSet.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// https://github.com/kubernetes/kubectl/blob/5366de04e168bcbc11f5e340d131a9ca8b7d0df4/pkg/cmd/exec/exec.go#L350-L352
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return nil, fmt.Errorf("cannot exec into a container in a completed pod; current phase is %s", pod.Status.Phase)
+	}
+	return pod, nil
+}
+
+// execInContainer executes the command in the Pod container, a non-zero exit code is reported in the result (not as an error)
+func (k *Kubernetes) execInContainer(ctx context.Context, namespace, name, container string, command []string, stdin string, timeout time.Duration) (*PodsExecResult, error) {
+	var stdinReader io.Reader
+	if stdin != "" {
+		stdinReader = strings.NewReader(stdin)
+	}
+	stdout := &limitedBuffer{max: PodsExecMaxOutputBytes}
+	stderr := &limitedBuffer{max: PodsExecMaxOutputBytes}
+	result := &PodsExecResult{Container: container}
+	var exitErr exec.ExitError
+	if err := k.streamInContainer(ctx, namespace, name, container, command, stdinReader, stdout, stderr, timeout); errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitStatus()
+	} else if err != nil {
+		return nil, err
+	}
+	result.Stdout, result.StdoutTruncated = stdout.String(), stdout.truncated
+	result.Stderr, result.StderrTruncated = stderr.String(), stderr.truncated
+	return result, nil
+}
+
+// limitedBuffer keeps the first max bytes written to it and discards the rest,
+// the writes never fail so that the command isn't interrupted by a large output
+type limitedBuffer struct {
+	buffer    bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.max - b.buffer.Len(); len(p) > remaining {
+		b.buffer.Write(p[:remaining])
+		b.truncated = true
+	} else {
+		b.buffer.Write(p)
+	}
+	return len(p), nil
+}
+
+// String returns the kept bytes, without the incomplete trailing rune if the output was truncated
+func (b *limitedBuffer) String() string {
+	kept := b.buffer.Bytes()
+	if b.truncated {
+		for i := len(kept) - 1; i >= 0 && i >= len(kept)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(kept[i]) {
+				if !utf8.FullRune(kept[i:]) {
+					kept = kept[:i]
+				}
+				break
+			}
+		}
+	}
+	return string(kept)
+}
+
+// streamInContainer executes the command in the Pod container streaming the provided standard input (if not nil) and outputs.
+// A non-zero exit code is returned as an exec.ExitError.
+func (k *Kubernetes) streamInContainer(ctx context.Context, namespace, name, container string, command []string, stdin io.Reader, stdout, stderr io.Writer, timeout time.Duration) error {
+	podExecOptions := &v1.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     stdin != nil,
+		Stdout:    true,
+		Stderr:    true,
+	}
+	executor, err := k.createExecutor(namespace, name, podExecOptions)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdin: stdin, Stdout: stdout, Stderr: stderr, Tty: false})
+	var exitErr exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("command timed out after %s: %w", timeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// execTimeout validates the provided command execution timeout, or returns the default one if not provided
+func execTimeout(timeout time.Duration) (time.Duration, error) {
+	if timeout == 0 {
+		return PodsExecDefaultTimeout, nil
+	}
+	if timeout < 0 || timeout > PodsExecMaxTimeout {
+		return 0, fmt.Errorf("timeout must be positive and must not exceed %s", PodsExecMaxTimeout)
+	}
+	return timeout, nil
+}
+
+// podContainer returns the name of the provided container after validating it exists in the Pod (including init and
+// ephemeral containers), or the default container of the Pod if none is provided
+func podContainer(pod *v1.Pod, container string) (string, error) {
+	if container == "" {
+		if defaultContainer := pod.Annotations[defaultContainerAnnotation]; defaultContainer != "" {
+			container = defaultContainer
+		} else if len(pod.Spec.Containers) > 0 {
+			return pod.Spec.Containers[0].Name, nil
+		}
+	}
+	var containers []string
+	for _, c := range pod.Spec.Containers {
+		containers = append(containers, c.Name)
+	}
+	for _, c := range pod.Spec.InitContainers {
+		containers = append(containers, c.Name)
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		containers = append(containers, c.Name)
+	}
+	for _, c := range containers {
+		if c == container {
+			return container, nil
+		}
+	}
+	return "", fmt.Errorf("container %s not found in pod %s, available containers: %s", container, pod.Name, strings.Join(containers, ", "))
+}
+
+func (k *Kubernetes) createExecutor(namespace, name string, podExecOptions *v1.PodExecOptions) (remotecommand.Executor, error) {
+	// Compute URL
+	// https://github.com/kubernetes/kubectl/blob/5366de04e168bcbc11f5e340d131a9ca8b7d0df4/pkg/cmd/exec/exec.go#L382-L397
+	req := k.clientSet.CoreV1().RESTClient().
+		Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(name).
+		SubResource("exec")
+	req.VersionedParams(podExecOptions, k.parameterCodec)
+	spdyExec, err := remotecommand.NewSPDYExecutor(k.cfg, "POST", req.URL())
+	if err != nil {
+		return nil, err
+	}
+	webSocketExec, err := remotecommand.NewWebSocketExecutor(k.cfg, "GET", req.URL().String())
+	if err != nil {
+		return nil, err
+	}
+	return remotecommand.NewFallbackExecutor(webSocketExec, spdyExec, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+}
@@ -0,0 +1,255 @@
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// PodsLogDefaultTailLines is the number of lines retrieved when neither the tail nor the since options are provided
+	PodsLogDefaultTailLines = int64(256)
+	// PodsLogMaxBytes is the hard limit of the returned logs size to protect the context window of the model,
+	// the most recent lines are kept
+	PodsLogMaxBytes = 64 * 1024
+	// PodsLogMaxStreamBytes is the limit of the log bytes retrieved from the API server (LimitBytes) when the logs are not followed,
+	// it bounds the transferred logs when most of the lines are filtered out or truncated to PodsLogMaxBytes
+	PodsLogMaxStreamBytes = int64(16 * PodsLogMaxBytes)
+)
+
+// PodsLogOptions are the options to retrieve and filter the logs of a Pod container
+type PodsLogOptions struct {
+	// Container to retrieve the logs from, the API server defaults to the only container of the Pod
+	Container string
+	// Previous retrieves the logs of the previous terminated container instance (e.g. CrashLoopBackOff)
+	Previous bool
+	// TailLines is the number of lines from the end of the logs to retrieve (PodsLogDefaultTailLines if 0 and no since option is set)
+	TailLines int64
+	// SinceSeconds retrieves the logs newer than the provided relative time in seconds
+	SinceSeconds int64
+	// SinceTime retrieves the logs newer than the provided time
+	SinceTime *metav1.Time
+	// Timestamps prefixes each line with its RFC3339 timestamp
+	Timestamps bool
+	// Filter keeps only the lines matching the regular expression
+	Filter *regexp.Regexp
+}
+
+func (o *PodsLogOptions) podLogOptions() (*v1.PodLogOptions, error) {
+	if o.SinceSeconds > 0 && o.SinceTime != nil {
+		return nil, errors.New("only one of sinceSeconds or sinceTime can be provided")
+	}
+	if o.TailLines < 0 || o.SinceSeconds < 0 {
+		return nil, errors.New("tail and sinceSeconds must be positive")
+	}
+	limitBytes := PodsLogMaxStreamBytes
+	podLogOptions := &v1.PodLogOptions{
+		Container:  o.Container,
+		Previous:   o.Previous,
+		Timestamps: o.Timestamps,
+		SinceTime:  o.SinceTime,
+		LimitBytes: &limitBytes,
+	}
+	if o.TailLines > 0 {
+		podLogOptions.TailLines = &o.TailLines
+	} else if o.SinceSeconds == 0 && o.SinceTime == nil {
+		tailLines := PodsLogDefaultTailLines
+		podLogOptions.TailLines = &tailLines
+	}
+	if o.SinceSeconds > 0 {
+		podLogOptions.SinceSeconds = &o.SinceSeconds
+	}
+	return podLogOptions, nil
+}
+
+func (k *Kubernetes) PodsLog(ctx context.Context, namespace, name string, options PodsLogOptions) (string, error) {
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}); err != nil {
+		return "", err
+	}
+	namespace, err := k.namespaceOrDefault(namespace)
+	if err != nil {
+		return "", err
+	}
+	podLogOptions, err := options.podLogOptions()
+	if err != nil {
+		return "", err
+	}
+	stream, err := k.clientSet.CoreV1().Pods(namespace).GetLogs(name, podLogOptions).Stream(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = stream.Close() }()
+	counter := &countingReader{Reader: stream}
+	log, err := readLog(counter, options.Filter, options.Timestamps, PodsLogMaxBytes)
+	if err != nil {
+		return "", err
+	}
+	if counter.count >= *podLogOptions.LimitBytes {
+		log = fmt.Sprintf("# The log exceeds the retrieval limit of %d bytes, the most recent lines may be missing\n", *podLogOptions.LimitBytes) + log
+	}
+	return log, nil
+}
+
+// PodsLogFollow streams the log lines of a Pod container matching the filter (if any) to onLine as they are written.
+// Following stops when the log stream ends (e.g. the container terminates), onLine returns false, or the context is done
+// (the context error is returned). Unless the tail or since options are provided, only the new lines are streamed.
+func (k *Kubernetes) PodsLogFollow(ctx context.Context, namespace, name string, options PodsLogOptions, onLine func(line string) bool) error {
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}); err != nil {
+		return err
+	}
+	namespace, err := k.namespaceOrDefault(namespace)
+	if err != nil {
+		return err
+	}
+	podLogOptions, err := options.podLogOptions()
+	if err != nil {
+		return err
+	}
+	// The followed log is streamed line by line, only the line size is bounded
+	podLogOptions.Follow, podLogOptions.LimitBytes = true, nil
+	if options.TailLines == 0 && options.SinceSeconds == 0 && options.SinceTime == nil {
+		tailLines := int64(0)
+		podLogOptions.TailLines = &tailLines
+	}
+	stream, err := k.clientSet.CoreV1().Pods(namespace).GetLogs(name, podLogOptions).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stream.Close() }()
+	reader := bufio.NewReader(stream)
+	for {
+		line, _, err := readLogLine(reader, PodsLogMaxBytes)
+		if len(line) > 0 && matchesLogFilter(line, options.Filter, options.Timestamps) {
+			if !onLine(line) {
+				return nil
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+	}
+}
+
+// readLog reads the log lines matching the filter (if any), only the most recent lines within maxBytes are kept
+func readLog(stream io.Reader, filter *regexp.Regexp, timestamps bool, maxBytes int) (string, error) {
+	lines, truncated, err := readLogLines(stream, filter, timestamps, maxBytes)
+	if err != nil {
+		return "", err
+	}
+	log := strings.Join(lines, "")
+	if truncated {
+		log = fmt.Sprintf("# The log exceeds %d bytes, only the most recent lines are returned\n", maxBytes) + log
+	}
+	return log, nil
+}
+
+// readLogLines reads the log lines matching the filter (if any), only the most recent lines within maxBytes are kept.
+// If the lines are prefixed with timestamps, the filter is applied to the message after the timestamp.
+func readLogLines(stream io.Reader, filter *regexp.Regexp, timestamps bool, maxBytes int) ([]string, bool, error) {
+	reader := bufio.NewReader(stream)
+	var lines []string
+	size := 0
+	truncated := false
+	for {
+		line, lineTruncated, err := readLogLine(reader, maxBytes)
+		if len(line) > 0 && matchesLogFilter(line, filter, timestamps) {
+			truncated = truncated || lineTruncated
+			lines = append(lines, line)
+			size += len(line)
+			for size > maxBytes && len(lines) > 0 {
+				size -= len(lines[0])
+				lines = lines[1:]
+				truncated = true
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, false, err
+		}
+	}
+	return lines, truncated, nil
+}
+
+// readLogLine reads the next log line terminated with a new line (appended if missing at the end of the stream).
+// Only the last maxBytes of the longer lines are kept, they are cut at a rune boundary and the line is reported as truncated.
+func readLogLine(reader *bufio.Reader, maxBytes int) (string, bool, error) {
+	var line []byte
+	truncated := false
+	for {
+		// ReadSlice returns the lines longer than the reader buffer in chunks so that they are never fully buffered
+		chunk, err := reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxBytes {
+			line, truncated = line[len(line)-maxBytes:], true
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if len(line) > 0 && line[len(line)-1] != '\n' {
+			if len(line) == maxBytes {
+				line, truncated = line[1:], true
+			}
+			line = append(line, '\n')
+		}
+		if truncated {
+			for i := 0; i < len(line) && i < utf8.UTFMax; i++ {
+				if utf8.RuneStart(line[i]) {
+					line = line[i:]
+					break
+				}
+			}
+		}
+		return string(line), truncated, err
+	}
+}
+
+// countingReader counts the bytes read from the underlying Reader
+type countingReader struct {
+	io.Reader
+	count int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+func matchesLogFilter(line string, filter *regexp.Regexp, timestamps bool) bool {
+	if filter == nil {
+		return true
+	}
+	line = strings.TrimSuffix(line, "\n")
+	if timestamps {
+		_, line = splitLogTimestamp(line)
+	}
+	return filter.MatchString(line)
+}
+
+// splitLogTimestamp splits a log line retrieved with the timestamps option into its timestamp and message
+func splitLogTimestamp(line string) (time.Time, string) {
+	timestamp, message, found := strings.Cut(line, " ")
+	if !found {
+		return time.Time{}, line
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, line
+	}
+	return parsed, message
+}
@@ -0,0 +1,119 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"path"
+	"strings"
+)
+
+// ErrResourceDenied is returned for any operation on a resource kind denied by the server configuration
+var ErrResourceDenied = errors.New("resource not allowed")
+
+// ErrNamespaceDenied is returned for any operation on a namespace not allowed by the server configuration
+var ErrNamespaceDenied = errors.New("namespace not allowed")
+
+// DeniedResource is a rule matching the GroupVersionKinds the server is not allowed to access.
+// Any of the fields can be a "*" wildcard.
+type DeniedResource struct {
+	Group   string
+	Version string
+	Kind    string
+}
+
+// ParseDeniedResource parses a rule in the "<apiVersion> <kind>" format (e.g. "v1 Secret", "rbac.authorization.k8s.io/* *").
+// The apiVersion can be a single "*" to match any group and version.
+func ParseDeniedResource(rule string) (DeniedResource, error) {
+	fields := strings.Fields(rule)
+	if len(fields) != 2 {
+		return DeniedResource{}, fmt.Errorf("invalid denied resource %q, expected format is \"<apiVersion> <kind>\" (e.g. \"v1 Secret\")", rule)
+	}
+	apiVersion, kind := fields[0], fields[1]
+	if apiVersion == "*" {
+		return DeniedResource{Group: "*", Version: "*", Kind: kind}, nil
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return DeniedResource{}, fmt.Errorf("invalid denied resource %q, %v", rule, err)
+	}
+	return DeniedResource{Group: gv.Group, Version: gv.Version, Kind: kind}, nil
+}
+
+func (d DeniedResource) Matches(gvk *schema.GroupVersionKind) bool {
+	return matchesOrWildcard(d.Group, gvk.Group) &&
+		matchesOrWildcard(d.Version, gvk.Version) &&
+		(d.Kind == "*" || strings.EqualFold(d.Kind, gvk.Kind))
+}
+
+func (d DeniedResource) String() string {
+	if d.Group == "" {
+		return d.Version + " " + d.Kind
+	}
+	if d.Group == "*" && d.Version == "*" {
+		return "* " + d.Kind
+	}
+	return d.Group + "/" + d.Version + " " + d.Kind
+}
+
+func matchesOrWildcard(pattern, value string) bool {
+	return pattern == "*" || pattern == value
+}
+
+// checkResourceAllowed returns an ErrResourceDenied error if the provided GroupVersionKind is denied by the configuration
+func (k *Kubernetes) checkResourceAllowed(gvk *schema.GroupVersionKind) error {
+	for _, deniedResource := range k.options.DeniedResources {
+		if deniedResource.Matches(gvk) {
+			return fmt.Errorf("%w: %s is denied by the server configuration (rule %q)",
+				ErrResourceDenied, gvk.GroupVersion().String()+" "+gvk.Kind, deniedResource.String())
+		}
+	}
+	return nil
+}
+
+// AllowedNamespace is an exact namespace name or a glob pattern (e.g. "team-a-*") matching the namespaces the server can access
+type AllowedNamespace string
+
+// ParseAllowedNamespace validates the provided namespace name or glob pattern
+func ParseAllowedNamespace(pattern string) (AllowedNamespace, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return "", errors.New("invalid allowed namespace, namespace name or pattern can't be empty")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return "", fmt.Errorf("invalid allowed namespace %q, %v", pattern, err)
+	}
+	return AllowedNamespace(pattern), nil
+}
+
+func (a AllowedNamespace) Matches(namespace string) bool {
+	matched, _ := path.Match(string(a), namespace)
+	return matched
+}
+
+// isNamespaceAllowed returns true if no allowed namespaces are configured or if any of them matches the provided namespace
+func (k *Kubernetes) isNamespaceAllowed(namespace string) bool {
+	if len(k.options.AllowedNamespaces) == 0 {
+		return true
+	}
+	for _, allowedNamespace := range k.options.AllowedNamespaces {
+		if allowedNamespace.Matches(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNamespaceAllowed returns an ErrNamespaceDenied error if the provided namespace is not allowed by the configuration
+func (k *Kubernetes) checkNamespaceAllowed(namespace string) error {
+	if !k.isNamespaceAllowed(namespace) {
+		return fmt.Errorf("%w: %q is not in the allowed namespaces of the server configuration", ErrNamespaceDenied, namespace)
+	}
+	return nil
+}
+
+// isNamespaceKind returns true for the kinds whose name is a namespace (Namespace and OpenShift Project)
+func isNamespaceKind(gvk *schema.GroupVersionKind) bool {
+	return (gvk.Group == "" && gvk.Kind == "Namespace") ||
+		(gvk.Group == "project.openshift.io" && gvk.Kind == "Project")
+}
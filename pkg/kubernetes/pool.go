@@ -0,0 +1,106 @@
+package kubernetes
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"reflect"
+	"sync"
+)
+
+// Pool holds lazily initialized Kubernetes clients keyed by kubeconfig context name.
+// The empty context name refers to the default client (in-cluster or kubeconfig current-context).
+type Pool struct {
+	mutex                sync.Mutex
+	options              Options
+	clients              map[string]*Kubernetes
+	kubeConfigFiles      []string
+	CloseWatchKubeConfig CloseWatchKubeConfig
+}
+
+func NewPool(options Options) *Pool {
+	return &Pool{
+		options:         options,
+		clients:         make(map[string]*Kubernetes),
+		kubeConfigFiles: resolveConfig(options.Kubeconfig, "").ConfigAccess().GetLoadingPrecedence(),
+	}
+}
+
+// SetOptions replaces the options used to create the pooled clients.
+// If the options changed, all the pooled clients are discarded and true is returned.
+func (p *Pool) SetOptions(options Options) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if reflect.DeepEqual(p.options, options) {
+		return false
+	}
+	p.options = options
+	p.clients = make(map[string]*Kubernetes)
+	p.kubeConfigFiles = resolveConfig(options.Kubeconfig, "").ConfigAccess().GetLoadingPrecedence()
+	return true
+}
+
+// Get returns the Kubernetes client for the provided context, creating it if it doesn't exist yet
+func (p *Pool) Get(kubeContext string) (*Kubernetes, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if k, ok := p.clients[kubeContext]; ok {
+		return k, nil
+	}
+	k, err := newKubernetes(p.options, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[kubeContext] = k
+	return k, nil
+}
+
+// Invalidate removes the pooled clients whose kubeconfig context, cluster or user have changed
+func (p *Pool) Invalidate() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for kubeContext, k := range p.clients {
+		if !reflect.DeepEqual(k.kubeConfigSnapshot, kubeConfigSnapshot(p.options.Kubeconfig, kubeContext)) {
+			delete(p.clients, kubeContext)
+		}
+	}
+}
+
+func (p *Pool) WatchKubeConfig(onKubeConfigChange func() error) {
+	p.mutex.Lock()
+	kubeConfigFiles := p.kubeConfigFiles
+	p.mutex.Unlock()
+	if len(kubeConfigFiles) == 0 {
+		return
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return
+	}
+	for _, file := range kubeConfigFiles {
+		_ = watcher.Add(file)
+	}
+	go func() {
+		for {
+			select {
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				_ = onKubeConfigChange()
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	if p.CloseWatchKubeConfig != nil {
+		_ = p.CloseWatchKubeConfig()
+	}
+	p.CloseWatchKubeConfig = watcher.Close
+}
+
+func (p *Pool) Close() {
+	if p.CloseWatchKubeConfig != nil {
+		_ = p.CloseWatchKubeConfig()
+	}
+}
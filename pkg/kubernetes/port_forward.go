@@ -0,0 +1,238 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	PortForwardTargetPod     = "pod"
+	PortForwardTargetService = "service"
+
+	// portForwardAddress is the local address the port-forwards listen on, they are never exposed outside the server host
+	portForwardAddress = "127.0.0.1"
+)
+
+// PortForward is an active port-forward from a local port to a Pod port
+type PortForward struct {
+	Namespace string `json:"namespace"`
+	// Target is the requested Pod or Service in <type>/<name> format
+	Target     string `json:"target"`
+	Pod        string `json:"pod"`
+	LocalPort  int    `json:"localPort"`
+	RemotePort int    `json:"remotePort"`
+	// LocalAddress is the host:port the port-forward listens on
+	LocalAddress string `json:"localAddress"`
+
+	stop     sync.Once
+	stopChan chan struct{}
+	done     chan struct{}
+	// err is the reason the port-forward terminated (if it wasn't stopped)
+	err error
+}
+
+// Stop closes the local listener and the connection to the Pod
+func (pf *PortForward) Stop() {
+	pf.stop.Do(func() { close(pf.stopChan) })
+	<-pf.done
+}
+
+// Done returns a channel that's closed when the port-forward terminates (stopped or lost connection to the Pod)
+func (pf *PortForward) Done() <-chan struct{} {
+	return pf.done
+}
+
+// Err returns the reason the port-forward terminated unexpectedly (nil if it's active or was stopped)
+func (pf *PortForward) Err() error {
+	select {
+	case <-pf.done:
+		return pf.err
+	default:
+		return nil
+	}
+}
+
+// PortForwardStart opens a port-forward from a local port (random if 0) to the port of the Pod, or of a running Pod
+// selected by the Service (the Service port is mapped to its target port).
+// The port-forward remains active after the context is done, until it's stopped.
+func (k *Kubernetes) PortForwardStart(ctx context.Context, namespace, targetType, name string, localPort, remotePort int) (*PortForward, error) {
+	if err := k.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}); err != nil {
+		return nil, err
+	}
+	if localPort < 0 || localPort > 65535 || remotePort <= 0 || remotePort > 65535 {
+		return nil, errors.New("ports must be between 1 and 65535 (local port 0 selects a random port)")
+	}
+	namespace, err := k.namespaceOrDefault(namespace)
+	if err != nil {
+		return nil, err
+	}
+	var pod *v1.Pod
+	switch targetType {
+	case PortForwardTargetPod:
+		if pod, err = k.clientSet.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+			return nil, err
+		}
+	case PortForwardTargetService:
+		if pod, remotePort, err = k.servicePod(ctx, namespace, name, remotePort); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid target type %s, valid values are: %s, %s", targetType, PortForwardTargetPod, PortForwardTargetService)
+	}
+	if pod.Status.Phase != v1.PodRunning {
+		return nil, fmt.Errorf("unable to forward port because pod %s is not running, current phase is %s", pod.Name, pod.Status.Phase)
+	}
+	dialer, err := k.createPortForwardDialer(namespace, pod.Name)
+	if err != nil {
+		return nil, err
+	}
+	pf := &PortForward{
+		Namespace:  namespace,
+		Target:     targetType + "/" + name,
+		Pod:        pod.Name,
+		RemotePort: remotePort,
+		stopChan:   make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	readyChan := make(chan struct{})
+	errOut := &lockedBuffer{}
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{portForwardAddress},
+		[]string{fmt.Sprintf("%d:%d", localPort, remotePort)}, pf.stopChan, readyChan, io.Discard, errOut)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		defer close(pf.done)
+		if err := forwarder.ForwardPorts(); err != nil {
+			pf.err = err
+		} else if msg := strings.TrimSpace(errOut.String()); msg != "" {
+			pf.err = errors.New(msg)
+		}
+	}()
+	select {
+	case <-readyChan:
+	case <-pf.done:
+		return nil, fmt.Errorf("failed to forward port: %w", pf.err)
+	case <-ctx.Done():
+		pf.Stop()
+		return nil, ctx.Err()
+	}
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		pf.Stop()
+		return nil, fmt.Errorf("failed to get forwarded port: %v", err)
+	}
+	pf.LocalPort = int(ports[0].Local)
+	pf.LocalAddress = net.JoinHostPort(portForwardAddress, strconv.Itoa(pf.LocalPort))
+	return pf, nil
+}
+
+// servicePod returns a running Pod selected by the Service and the Pod port targeted by the provided Service port
+func (k *Kubernetes) servicePod(ctx context.Context, namespace, name string, servicePort int) (*v1.Pod, int, error) {
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"}); err != nil {
+		return nil, 0, err
+	}
+	service, err := k.clientSet.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(service.Spec.Selector) == 0 {
+		return nil, 0, fmt.Errorf("service %s has no pod selector", name)
+	}
+	var port *v1.ServicePort
+	for i := range service.Spec.Ports {
+		if int(service.Spec.Ports[i].Port) == servicePort {
+			port = &service.Spec.Ports[i]
+		}
+	}
+	if port == nil {
+		return nil, 0, fmt.Errorf("service %s does not expose port %d", name, servicePort)
+	}
+	pods, err := k.clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		switch port.TargetPort.Type {
+		case intstr.Int:
+			if port.TargetPort.IntVal == 0 {
+				return pod, servicePort, nil
+			}
+			return pod, int(port.TargetPort.IntVal), nil
+		case intstr.String:
+			for _, container := range pod.Spec.Containers {
+				for _, containerPort := range container.Ports {
+					if containerPort.Name == port.TargetPort.StrVal {
+						return pod, int(containerPort.ContainerPort), nil
+					}
+				}
+			}
+			return nil, 0, fmt.Errorf("pod %s does not expose the named port %s targeted by service %s", pod.Name, port.TargetPort.StrVal, name)
+		}
+	}
+	return nil, 0, fmt.Errorf("no running pods found for service %s", name)
+}
+
+func (k *Kubernetes) createPortForwardDialer(namespace, name string) (httpstream.Dialer, error) {
+	req := k.clientSet.CoreV1().RESTClient().
+		Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(name).
+		SubResource("portforward")
+	transport, upgrader, err := spdy.RoundTripperFor(k.cfg)
+	if err != nil {
+		return nil, err
+	}
+	spdyDialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+	webSocketDialer, err := portforward.NewSPDYOverWebsocketDialer(req.URL(), k.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return portforward.NewFallbackDialer(webSocketDialer, spdyDialer, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	}), nil
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use (the port-forward connections write their errors concurrently)
+type lockedBuffer struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffer.String()
+}
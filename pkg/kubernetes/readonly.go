@@ -0,0 +1,46 @@
+package kubernetes
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrReadOnly is returned for any request that would modify the cluster while running in read-only mode
+var ErrReadOnly = errors.New("operation not allowed, the server is running in read-only mode")
+
+// readOnlyRoundTripper rejects any request to the Kubernetes API server that might modify the cluster state
+type readOnlyRoundTripper struct {
+	delegate http.RoundTripper
+}
+
+func (rt *readOnlyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isMutatingRequest(req) {
+		return nil, ErrReadOnly
+	}
+	return rt.delegate.RoundTrip(req)
+}
+
+func isMutatingRequest(req *http.Request) bool {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		// exec, attach and port-forward are upgraded GET requests (WebSockets)
+		return strings.HasSuffix(path, "/exec") ||
+			strings.HasSuffix(path, "/attach") ||
+			strings.HasSuffix(path, "/portforward")
+	case http.MethodPost:
+		// Access reviews are created but don't modify the cluster state
+		return !strings.HasSuffix(path, "/selfsubjectaccessreviews") &&
+			!strings.HasSuffix(path, "/selfsubjectrulesreviews")
+	default:
+		return true
+	}
+}
+
+func (k *Kubernetes) checkWritable() error {
+	if k.options.ReadOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
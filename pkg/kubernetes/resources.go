@@ -2,13 +2,17 @@ package kubernetes
 
 import (
 	"context"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
 	"github.com/manusa/kubernetes-mcp-server/pkg/version"
 	authv1 "k8s.io/api/authorization/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -32,9 +36,13 @@ func (k *Kubernetes) ResourcesGet(ctx context.Context, gvk *schema.GroupVersionK
 	if err != nil {
 		return "", err
 	}
-	// If it's a namespaced resource and namespace wasn't provided, try to use the default configured one
-	if namespaced, nsErr := k.isNamespaced(gvk); nsErr == nil && namespaced {
-		namespace = namespaceOrDefault(namespace)
+	if namespace, err = k.resourceNamespace(gvk, namespace); err != nil {
+		return "", err
+	}
+	if isNamespaceKind(gvk) {
+		if err = k.checkNamespaceAllowed(name); err != nil {
+			return "", err
+		}
 	}
 	rg, err := k.dynamicClient.Resource(*gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
@@ -58,15 +66,39 @@ func (k *Kubernetes) ResourcesCreateOrUpdate(ctx context.Context, resource strin
 }
 
 func (k *Kubernetes) ResourcesDelete(ctx context.Context, gvk *schema.GroupVersionKind, namespace, name string) error {
+	if err := k.checkWritable(); err != nil {
+		return err
+	}
 	gvr, err := k.resourceFor(gvk)
 	if err != nil {
 		return err
 	}
-	// If it's a namespaced resource and namespace wasn't provided, try to use the default configured one
-	if namespaced, nsErr := k.isNamespaced(gvk); nsErr == nil && namespaced {
-		namespace = namespaceOrDefault(namespace)
+	if namespace, err = k.resourceNamespace(gvk, namespace); err != nil {
+		return err
 	}
-	return k.dynamicClient.Resource(*gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if isNamespaceKind(gvk) {
+		if err = k.checkNamespaceAllowed(name); err != nil {
+			return err
+		}
+	}
+	resourceInterface := k.dynamicClient.Resource(*gvr).Namespace(namespace)
+	// Retrieve the object identity (UID and resourceVersion) for the audit log before it's deleted.
+	// The retrieval is best-effort, if it fails (e.g. the user can delete but not get) only the name is recorded.
+	if audit.RecordFrom(ctx) != nil {
+		obj, getErr := resourceInterface.Get(ctx, name, metav1.GetOptions{})
+		if getErr != nil {
+			klog.V(1).ErrorS(getErr, "Failed to retrieve the object identity for the audit log before deletion", "kind", gvk.Kind, "namespace", namespace, "name", name)
+			obj = &unstructured.Unstructured{}
+			obj.SetNamespace(namespace)
+			obj.SetName(name)
+		}
+		if err = resourceInterface.Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+		audit.AddObject(ctx, audit.ActionDelete, *gvk, obj)
+		return nil
+	}
+	return resourceInterface.Delete(ctx, name, metav1.DeleteOptions{})
 }
 
 func (k *Kubernetes) resourcesList(ctx context.Context, gvk *schema.GroupVersionKind, namespace string) (*unstructured.UnstructuredList, error) {
@@ -75,24 +107,52 @@ func (k *Kubernetes) resourcesList(ctx context.Context, gvk *schema.GroupVersion
 		return nil, err
 	}
 	// Check if operation is allowed for all namespaces (applicable for namespaced resources)
-	isNamespaced, _ := k.isNamespaced(gvk)
+	isNamespaced, err := k.isNamespaced(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the scope of %s %s: %w", gvk.GroupVersion().String(), gvk.Kind, err)
+	}
 	if isNamespaced && !k.canIUse(ctx, gvr, namespace, "list") && namespace == "" {
-		namespace = configuredNamespace()
+		namespace = k.configuredNamespace()
+	}
+	if isNamespaced && namespace != "" {
+		if err = k.checkNamespaceAllowed(namespace); err != nil {
+			return nil, err
+		}
+	}
+	rl, err := k.dynamicClient.Resource(*gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
 	}
-	return k.dynamicClient.Resource(*gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	// Filter out the resources (or namespaces) outside the allowed namespaces when listing across all namespaces
+	if len(k.options.AllowedNamespaces) > 0 && ((isNamespaced && namespace == "") || isNamespaceKind(gvk)) {
+		rl.Items = slices.DeleteFunc(rl.Items, func(item unstructured.Unstructured) bool {
+			if isNamespaceKind(gvk) {
+				return !k.isNamespaceAllowed(item.GetName())
+			}
+			return !k.isNamespaceAllowed(item.GetNamespace())
+		})
+	}
+	return rl, nil
 }
 
 func (k *Kubernetes) resourcesCreateOrUpdate(ctx context.Context, resources []*unstructured.Unstructured) (string, error) {
+	if err := k.checkWritable(); err != nil {
+		return "", err
+	}
 	for i, obj := range resources {
 		gvk := obj.GroupVersionKind()
 		gvr, rErr := k.resourceFor(&gvk)
 		if rErr != nil {
 			return "", rErr
 		}
-		namespace := obj.GetNamespace()
-		// If it's a namespaced resource and namespace wasn't provided, try to use the default configured one
-		if namespaced, nsErr := k.isNamespaced(&gvk); nsErr == nil && namespaced {
-			namespace = namespaceOrDefault(namespace)
+		namespace, rErr := k.resourceNamespace(&gvk, obj.GetNamespace())
+		if rErr != nil {
+			return "", rErr
+		}
+		if isNamespaceKind(&gvk) {
+			if rErr = k.checkNamespaceAllowed(obj.GetName()); rErr != nil {
+				return "", rErr
+			}
 		}
 		resources[i], rErr = k.dynamicClient.Resource(*gvr).Namespace(namespace).Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
 			FieldManager: version.BinaryName,
@@ -100,6 +160,7 @@ func (k *Kubernetes) resourcesCreateOrUpdate(ctx context.Context, resources []*u
 		if rErr != nil {
 			return "", rErr
 		}
+		audit.AddObject(ctx, audit.ActionApply, gvk, resources[i])
 		// Clear the cache to ensure the next operation is performed on the latest exposed APIs
 		if gvk.Kind == "CustomResourceDefinition" {
 			k.deferredDiscoveryRESTMapper.Reset()
@@ -112,14 +173,42 @@ func (k *Kubernetes) resourcesCreateOrUpdate(ctx context.Context, resources []*u
 	return "# The following resources (YAML) have been created or updated successfully\n" + marshalledYaml, nil
 }
 
+// resourceFor resolves the GroupVersionResource for the provided GroupVersionKind.
+// Every generic resource operation goes through this function, so it's also where the denied resources policy is enforced.
 func (k *Kubernetes) resourceFor(gvk *schema.GroupVersionKind) (*schema.GroupVersionResource, error) {
+	if err := k.checkResourceAllowed(gvk); err != nil {
+		return nil, err
+	}
 	m, err := k.deferredDiscoveryRESTMapper.RESTMapping(schema.GroupKind{Group: gvk.Group, Kind: gvk.Kind}, gvk.Version)
 	if err != nil {
 		return nil, err
 	}
+	// Check again with the resolved (canonical) GroupVersionKind
+	if err = k.checkResourceAllowed(&m.GroupVersionKind); err != nil {
+		return nil, err
+	}
 	return &m.Resource, nil
 }
 
+// resourceNamespace returns the namespace of a resource operation, the default configured one if it's a namespaced resource
+// and the namespace wasn't provided. The allowed namespaces are always enforced, if the resource scope can't be
+// discovered the operation fails instead of skipping the check.
+func (k *Kubernetes) resourceNamespace(gvk *schema.GroupVersionKind, namespace string) (string, error) {
+	namespaced, err := k.isNamespaced(gvk)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the scope of %s %s: %w", gvk.GroupVersion().String(), gvk.Kind, err)
+	}
+	if namespaced {
+		return k.namespaceOrDefault(namespace)
+	}
+	if namespace != "" {
+		if err = k.checkNamespaceAllowed(namespace); err != nil {
+			return "", err
+		}
+	}
+	return namespace, nil
+}
+
 func (k *Kubernetes) isNamespaced(gvk *schema.GroupVersionKind) (bool, error) {
 	apiResourceList, err := k.discoveryClient.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
 	if err != nil {
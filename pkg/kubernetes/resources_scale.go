@@ -0,0 +1,193 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"time"
+)
+
+const (
+	// ResourcesScaleDefaultTimeout is the maximum time to wait for the ready replicas if no timeout is provided
+	ResourcesScaleDefaultTimeout = 2 * time.Minute
+	// ResourcesScaleMaxTimeout is the upper limit of the time to wait for the ready replicas
+	ResourcesScaleMaxTimeout = 10 * time.Minute
+	// resourcesScalePollInterval is the interval between the checks of the ready replicas
+	resourcesScalePollInterval = time.Second
+)
+
+// ResourcesScaleOptions are the options to scale a resource
+type ResourcesScaleOptions struct {
+	// Replicas is the desired number of replicas (the scale is only read if nil)
+	Replicas *int64
+	// Wait until the ready replicas match the desired replicas
+	Wait bool
+	// Timeout is the maximum time to wait for the ready replicas (ResourcesScaleDefaultTimeout if 0)
+	Timeout time.Duration
+}
+
+// ResourcesScaleResult is the scale of a resource before and after scaling
+type ResourcesScaleResult struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// PreviousReplicas is the desired number of replicas before scaling (only if scaled)
+	PreviousReplicas *int64 `json:"previousReplicas,omitempty"`
+	// Replicas is the desired number of replicas
+	Replicas int64 `json:"replicas"`
+	// CurrentReplicas is the number of replicas reported by the scale subresource
+	CurrentReplicas int64 `json:"currentReplicas"`
+	// ReadyReplicas is the number of ready replicas (if the resource reports them)
+	ReadyReplicas *int64 `json:"readyReplicas,omitempty"`
+}
+
+// ResourcesScale reads, and optionally sets, the replicas of a resource through its scale subresource
+// (Deployments, StatefulSets, ReplicaSets, or any custom resource exposing a scale subresource)
+func (k *Kubernetes) ResourcesScale(ctx context.Context, gvk *schema.GroupVersionKind, namespace, name string, options ResourcesScaleOptions) (string, error) {
+	if options.Replicas != nil {
+		if err := k.checkWritable(); err != nil {
+			return "", err
+		}
+		if *options.Replicas < 0 {
+			return "", errors.New("replicas must not be negative")
+		}
+	}
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = ResourcesScaleDefaultTimeout
+	} else if timeout < 0 || timeout > ResourcesScaleMaxTimeout {
+		return "", fmt.Errorf("timeout must be positive and must not exceed %s", ResourcesScaleMaxTimeout)
+	}
+	gvr, err := k.resourceFor(gvk)
+	if err != nil {
+		return "", err
+	}
+	if !k.isScalable(gvr) {
+		return "", fmt.Errorf("%s %s does not support scaling (no scale subresource)", gvk.GroupVersion().String(), gvk.Kind)
+	}
+	if namespace, err = k.resourceNamespace(gvk, namespace); err != nil {
+		return "", err
+	}
+	resourceInterface := k.dynamicClient.Resource(*gvr).Namespace(namespace)
+	scale, err := resourceInterface.Get(ctx, name, metav1.GetOptions{}, "scale")
+	if err != nil {
+		return "", err
+	}
+	result := &ResourcesScaleResult{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Namespace:  namespace,
+		Name:       name,
+		Replicas:   nestedInt64(scale.Object, "spec", "replicas"),
+	}
+	if options.Replicas != nil {
+		previousReplicas := result.Replicas
+		result.PreviousReplicas = &previousReplicas
+		patch, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"replicas": *options.Replicas}})
+		if scale, err = resourceInterface.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "scale"); err != nil {
+			return "", err
+		}
+		audit.AddObject(ctx, audit.ActionApply, *gvk, scale)
+		result.Replicas = nestedInt64(scale.Object, "spec", "replicas")
+	}
+	result.CurrentReplicas = nestedInt64(scale.Object, "status", "replicas")
+	if result.ReadyReplicas, err = readyReplicas(ctx, resourceInterface, name); err != nil {
+		return "", err
+	}
+	if options.Wait {
+		if err = waitForReadyReplicas(ctx, resourceInterface, name, result, timeout); err != nil {
+			return "", err
+		}
+	}
+	return marshal(result)
+}
+
+// waitForReadyReplicas waits until the current and ready replicas match the desired replicas, the result is updated on every check
+func waitForReadyReplicas(ctx context.Context, resourceInterface dynamic.ResourceInterface, name string, result *ResourcesScaleResult, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := wait.PollUntilContextCancel(waitCtx, resourcesScalePollInterval, true, func(ctx context.Context) (bool, error) {
+		scale, err := resourceInterface.Get(ctx, name, metav1.GetOptions{}, "scale")
+		if err != nil {
+			return false, err
+		}
+		result.CurrentReplicas = nestedInt64(scale.Object, "status", "replicas")
+		if result.ReadyReplicas, err = readyReplicas(ctx, resourceInterface, name); err != nil {
+			return false, err
+		}
+		// Resources that don't report their ready replicas are considered ready once the current replicas match
+		ready := result.CurrentReplicas
+		if result.ReadyReplicas != nil {
+			ready = *result.ReadyReplicas
+		}
+		return result.CurrentReplicas == result.Replicas && ready == result.Replicas, nil
+	})
+	// The client might fail before the poll when the deadline is close (rate limiter), so the context is checked instead of the error
+	if err != nil && ctx.Err() == nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+		readyReplicas := "unknown"
+		if result.ReadyReplicas != nil {
+			readyReplicas = fmt.Sprint(*result.ReadyReplicas)
+		}
+		return fmt.Errorf("timed out after %s waiting for %d ready replicas (current replicas: %d, ready replicas: %s): %w",
+			timeout, result.Replicas, result.CurrentReplicas, readyReplicas, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// readyReplicas returns the status.readyReplicas of the resource, or nil if the resource doesn't report them
+func readyReplicas(ctx context.Context, resourceInterface dynamic.ResourceInterface, name string) (*int64, error) {
+	obj, err := resourceInterface.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	status, found, _ := unstructured.NestedMap(obj.Object, "status")
+	if !found {
+		return nil, nil
+	}
+	// The readyReplicas field is omitted when there are no ready replicas
+	if _, hasReplicas := status["replicas"]; !hasReplicas {
+		if _, hasReady := status["readyReplicas"]; !hasReady {
+			return nil, nil
+		}
+	}
+	readyReplicas := nestedInt64(obj.Object, "status", "readyReplicas")
+	return &readyReplicas, nil
+}
+
+// isScalable returns true if the resource exposes a scale subresource
+func (k *Kubernetes) isScalable(gvr *schema.GroupVersionResource) bool {
+	apiResourceList, err := k.discoveryClient.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return false
+	}
+	for _, apiResource := range apiResourceList.APIResources {
+		if apiResource.Name == gvr.Resource+"/scale" {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedInt64 returns the integer field of the object (numbers might be decoded as int64 or float64), 0 if not found
+func nestedInt64(obj map[string]interface{}, fields ...string) int64 {
+	value, found, _ := unstructured.NestedFieldNoCopy(obj, fields...)
+	if !found {
+		return 0
+	}
+	switch v := value.(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
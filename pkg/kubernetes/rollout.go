@@ -0,0 +1,467 @@
+package kubernetes
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RolloutStatusDefaultTimeout is the maximum time to wait for a rollout to complete if no timeout is provided
+	RolloutStatusDefaultTimeout = 2 * time.Minute
+	// RolloutStatusMaxTimeout is the upper limit of the time to wait for a rollout to complete
+	RolloutStatusMaxTimeout = 10 * time.Minute
+	// rolloutStatusPollInterval is the interval between the checks of the rollout status
+	rolloutStatusPollInterval = 2 * time.Second
+
+	// restartedAtAnnotation is the Pod template annotation kubectl rollout restart sets to trigger a rollout
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+	// changeCauseAnnotation is the annotation that records the cause of a revision
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+	// revisionAnnotation is the annotation of the revision of a Deployment ReplicaSet
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// RolloutKinds are the workload kinds whose rollouts can be managed
+var RolloutKinds = []string{"Deployment", "StatefulSet", "DaemonSet"}
+
+// rolloutAnnotationsToSkip are the Deployment annotations that are not copied from the ReplicaSet on rollback (as kubectl rollout undo)
+var rolloutAnnotationsToSkip = map[string]bool{
+	v1.LastAppliedConfigAnnotation:              true,
+	revisionAnnotation:                          true,
+	"deployment.kubernetes.io/revision-history": true,
+	"deployment.kubernetes.io/desired-replicas": true,
+	"deployment.kubernetes.io/max-replicas":     true,
+	appsv1.DeprecatedRollbackTo:                 true,
+}
+
+// RolloutStatusOptions are the options to get the rollout status of a workload
+type RolloutStatusOptions struct {
+	// Wait until the rollout completes or fails
+	Wait bool
+	// Timeout is the maximum time to wait for the rollout to complete (RolloutStatusDefaultTimeout if 0)
+	Timeout time.Duration
+}
+
+// RolloutStatusResult is the rollout status of a workload, as reported by kubectl rollout status
+type RolloutStatusResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Done is true if the rollout completed
+	Done       bool               `json:"done"`
+	Message    string             `json:"message"`
+	Conditions []RolloutCondition `json:"conditions,omitempty"`
+}
+
+// RolloutCondition is a status condition of a workload (e.g. Progressing and Available for Deployments)
+type RolloutCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// RolloutRevision is a revision of the rollout history of a workload
+type RolloutRevision struct {
+	Revision    int64     `json:"revision"`
+	ChangeCause string    `json:"changeCause,omitempty"`
+	Name        string    `json:"name"`
+	Created     time.Time `json:"created"`
+	Images      []string  `json:"images,omitempty"`
+	// template and annotations are the Pod template and annotations of a Deployment revision (ReplicaSet)
+	template    *v1.PodTemplateSpec
+	annotations map[string]string
+	// data is the strategic merge patch of a StatefulSet or DaemonSet revision
+	data []byte
+}
+
+// RolloutStatus returns the rollout status of the workload, optionally waiting until the rollout completes
+func (k *Kubernetes) RolloutStatus(ctx context.Context, namespace, kind, name string, options RolloutStatusOptions) (string, error) {
+	namespace, err := k.rolloutTarget(namespace, kind)
+	if err != nil {
+		return "", err
+	}
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = RolloutStatusDefaultTimeout
+	} else if timeout < 0 || timeout > RolloutStatusMaxTimeout {
+		return "", fmt.Errorf("timeout must be positive and must not exceed %s", RolloutStatusMaxTimeout)
+	}
+	result, err := k.rolloutStatus(ctx, namespace, kind, name)
+	if err != nil {
+		return "", err
+	}
+	if result.Done || !options.Wait {
+		return marshal(result)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err = wait.PollUntilContextCancel(waitCtx, rolloutStatusPollInterval, false, func(ctx context.Context) (bool, error) {
+		status, err := k.rolloutStatus(ctx, namespace, kind, name)
+		if err != nil {
+			return false, err
+		}
+		result = status
+		return result.Done, nil
+	})
+	// The client might fail before the poll when the deadline is close (rate limiter), so the context is checked instead of the error
+	if err != nil && ctx.Err() == nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("timed out after %s waiting for the rollout to complete, %s: %w", timeout, strings.TrimSuffix(result.Message, "..."), context.DeadlineExceeded)
+	} else if err != nil {
+		return "", err
+	}
+	return marshal(result)
+}
+
+// rolloutStatus implements the kubectl rollout status checks of the workload kinds
+// https://github.com/kubernetes/kubectl/blob/master/pkg/polymorphichelpers/rollout_status.go
+func (k *Kubernetes) rolloutStatus(ctx context.Context, namespace, kind, name string) (*RolloutStatusResult, error) {
+	result := &RolloutStatusResult{Kind: kind, Namespace: namespace, Name: name}
+	switch kind {
+	case "Deployment":
+		deployment, err := k.clientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range deployment.Status.Conditions {
+			result.Conditions = append(result.Conditions, RolloutCondition{Type: string(c.Type), Status: string(c.Status), Reason: c.Reason, Message: c.Message})
+		}
+		status := deployment.Status
+		switch {
+		case deployment.Generation > status.ObservedGeneration:
+			result.Message = "Waiting for deployment spec update to be observed..."
+		case slices.ContainsFunc(status.Conditions, func(c appsv1.DeploymentCondition) bool {
+			return c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded"
+		}):
+			return nil, fmt.Errorf("deployment %q exceeded its progress deadline", name)
+		case deployment.Spec.Replicas != nil && status.UpdatedReplicas < *deployment.Spec.Replicas:
+			result.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...", name, status.UpdatedReplicas, *deployment.Spec.Replicas)
+		case status.Replicas > status.UpdatedReplicas:
+			result.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination...", name, status.Replicas-status.UpdatedReplicas)
+		case status.AvailableReplicas < status.UpdatedReplicas:
+			result.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...", name, status.AvailableReplicas, status.UpdatedReplicas)
+		default:
+			result.Done, result.Message = true, fmt.Sprintf("deployment %q successfully rolled out", name)
+		}
+	case "StatefulSet":
+		statefulSet, err := k.clientSet.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if statefulSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+			return nil, fmt.Errorf("rollout status is only available for %s strategy type", appsv1.RollingUpdateStatefulSetStrategyType)
+		}
+		for _, c := range statefulSet.Status.Conditions {
+			result.Conditions = append(result.Conditions, RolloutCondition{Type: string(c.Type), Status: string(c.Status), Reason: c.Reason, Message: c.Message})
+		}
+		status := statefulSet.Status
+		rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate
+		switch {
+		case status.ObservedGeneration == 0 || statefulSet.Generation > status.ObservedGeneration:
+			result.Message = "Waiting for statefulset spec update to be observed..."
+		case statefulSet.Spec.Replicas != nil && status.ReadyReplicas < *statefulSet.Spec.Replicas:
+			result.Message = fmt.Sprintf("Waiting for %d pods to be ready...", *statefulSet.Spec.Replicas-status.ReadyReplicas)
+		case rollingUpdate != nil && rollingUpdate.Partition != nil && statefulSet.Spec.Replicas != nil:
+			if status.UpdatedReplicas < *statefulSet.Spec.Replicas-*rollingUpdate.Partition {
+				result.Message = fmt.Sprintf("Waiting for partitioned roll out to finish: %d out of %d new pods have been updated...",
+					status.UpdatedReplicas, *statefulSet.Spec.Replicas-*rollingUpdate.Partition)
+			} else {
+				result.Done, result.Message = true, fmt.Sprintf("partitioned roll out complete: %d new pods have been updated...", status.UpdatedReplicas)
+			}
+		case status.UpdateRevision != status.CurrentRevision:
+			result.Message = fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s...", status.UpdatedReplicas, status.UpdateRevision)
+		default:
+			result.Done, result.Message = true, fmt.Sprintf("statefulset rolling update complete %d pods at revision %s...", status.CurrentReplicas, status.CurrentRevision)
+		}
+	case "DaemonSet":
+		daemonSet, err := k.clientSet.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if daemonSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+			return nil, fmt.Errorf("rollout status is only available for %s strategy type", appsv1.RollingUpdateDaemonSetStrategyType)
+		}
+		for _, c := range daemonSet.Status.Conditions {
+			result.Conditions = append(result.Conditions, RolloutCondition{Type: string(c.Type), Status: string(c.Status), Reason: c.Reason, Message: c.Message})
+		}
+		status := daemonSet.Status
+		switch {
+		case daemonSet.Generation > status.ObservedGeneration:
+			result.Message = "Waiting for daemon set spec update to be observed..."
+		case status.UpdatedNumberScheduled < status.DesiredNumberScheduled:
+			result.Message = fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d out of %d new pods have been updated...", name, status.UpdatedNumberScheduled, status.DesiredNumberScheduled)
+		case status.NumberAvailable < status.DesiredNumberScheduled:
+			result.Message = fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d of %d updated pods are available...", name, status.NumberAvailable, status.DesiredNumberScheduled)
+		default:
+			result.Done, result.Message = true, fmt.Sprintf("daemon set %q successfully rolled out", name)
+		}
+	}
+	return result, nil
+}
+
+// RolloutRestart triggers a rollout of the workload by setting the restartedAt annotation of its Pod template (as kubectl rollout restart)
+func (k *Kubernetes) RolloutRestart(ctx context.Context, namespace, kind, name string) (string, error) {
+	if err := k.checkWritable(); err != nil {
+		return "", err
+	}
+	namespace, err := k.rolloutTarget(namespace, kind)
+	if err != nil {
+		return "", err
+	}
+	if kind == "Deployment" {
+		deployment, err := k.clientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		if deployment.Spec.Paused {
+			return "", fmt.Errorf("can't restart paused deployment %s (resume it first)", name)
+		}
+	}
+	patch, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]string{restartedAtAnnotation: time.Now().Format(time.RFC3339)}},
+	}}})
+	if err = k.rolloutPatch(ctx, namespace, kind, name, types.StrategicMergePatchType, patch); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s in namespace %s restarted (use rollout_status to follow the rollout)", kind, name, namespace), nil
+}
+
+// RolloutHistory returns the revisions of the workload (ReplicaSets for Deployments, ControllerRevisions for StatefulSets and DaemonSets)
+func (k *Kubernetes) RolloutHistory(ctx context.Context, namespace, kind, name string) (string, error) {
+	namespace, err := k.rolloutTarget(namespace, kind)
+	if err != nil {
+		return "", err
+	}
+	history, err := k.rolloutHistory(ctx, namespace, kind, name)
+	if err != nil {
+		return "", err
+	}
+	if len(history) == 0 {
+		return fmt.Sprintf("No rollout history found for %s %s in namespace %s", kind, name, namespace), nil
+	}
+	yamlHistory, err := marshal(history)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("The following revisions (YAML format) were found for %s %s:\n%s", kind, name, yamlHistory), nil
+}
+
+// RolloutUndo rolls back the workload to the provided revision, or to the previous revision if 0 (as kubectl rollout undo)
+func (k *Kubernetes) RolloutUndo(ctx context.Context, namespace, kind, name string, toRevision int64) (string, error) {
+	if err := k.checkWritable(); err != nil {
+		return "", err
+	}
+	if toRevision < 0 {
+		return "", errors.New("revision must not be negative")
+	}
+	namespace, err := k.rolloutTarget(namespace, kind)
+	if err != nil {
+		return "", err
+	}
+	history, err := k.rolloutHistory(ctx, namespace, kind, name)
+	if err != nil {
+		return "", err
+	}
+	if len(history) == 0 {
+		return "", fmt.Errorf("no rollout history found for %s %s", kind, name)
+	}
+	current := history[len(history)-1]
+	var target *RolloutRevision
+	if toRevision == 0 {
+		if len(history) < 2 {
+			return "", fmt.Errorf("no previous revision found for %s %s", kind, name)
+		}
+		target = history[len(history)-2]
+	} else if i := slices.IndexFunc(history, func(r *RolloutRevision) bool { return r.Revision == toRevision }); i >= 0 {
+		target = history[i]
+	} else {
+		return "", fmt.Errorf("unable to find the specified revision %d in the history of %s %s", toRevision, kind, name)
+	}
+	if kind == "Deployment" {
+		return k.deploymentRollback(ctx, namespace, name, target)
+	}
+	if target == current {
+		return fmt.Sprintf("Skipped rollback of %s %s (current template already matches revision %d)", kind, name, target.Revision), nil
+	}
+	if err = k.rolloutPatch(ctx, namespace, kind, name, types.StrategicMergePatchType, target.data); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s in namespace %s rolled back to revision %d", kind, name, namespace, target.Revision), nil
+}
+
+// deploymentRollback replaces the Pod template of the Deployment with the one of the ReplicaSet revision
+func (k *Kubernetes) deploymentRollback(ctx context.Context, namespace, name string, target *RolloutRevision) (string, error) {
+	deployment, err := k.clientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if deployment.Spec.Paused {
+		return "", fmt.Errorf("can't rollback paused deployment %s (resume it first)", name)
+	}
+	template := target.template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	if equality.Semantic.DeepEqual(template, &deployment.Spec.Template) {
+		return fmt.Sprintf("Skipped rollback of Deployment %s (current template already matches revision %d)", name, target.Revision), nil
+	}
+	annotations := map[string]string{}
+	for key := range rolloutAnnotationsToSkip {
+		if value, ok := deployment.Annotations[key]; ok {
+			annotations[key] = value
+		}
+	}
+	for key, value := range target.annotations {
+		if !rolloutAnnotationsToSkip[key] {
+			annotations[key] = value
+		}
+	}
+	patch, _ := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/spec/template", "value": template},
+		{"op": "replace", "path": "/metadata/annotations", "value": annotations},
+	})
+	if err = k.rolloutPatch(ctx, namespace, "Deployment", name, types.JSONPatchType, patch); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Deployment %s in namespace %s rolled back to revision %d", name, namespace, target.Revision), nil
+}
+
+// rolloutHistory returns the revisions of the workload sorted by revision number
+func (k *Kubernetes) rolloutHistory(ctx context.Context, namespace, kind, name string) ([]*RolloutRevision, error) {
+	var owner metav1.Object
+	var selector *metav1.LabelSelector
+	switch kind {
+	case "Deployment":
+		deployment, err := k.clientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner, selector = deployment, deployment.Spec.Selector
+	case "StatefulSet":
+		statefulSet, err := k.clientSet.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner, selector = statefulSet, statefulSet.Spec.Selector
+	case "DaemonSet":
+		daemonSet, err := k.clientSet.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner, selector = daemonSet, daemonSet.Spec.Selector
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	listOptions := metav1.ListOptions{LabelSelector: labelSelector.String()}
+	history := make([]*RolloutRevision, 0)
+	if kind == "Deployment" {
+		replicaSets, err := k.clientSet.AppsV1().ReplicaSets(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range replicaSets.Items {
+			replicaSet := &replicaSets.Items[i]
+			revision, err := strconv.ParseInt(replicaSet.Annotations[revisionAnnotation], 10, 64)
+			if !metav1.IsControlledBy(replicaSet, owner) || err != nil {
+				continue
+			}
+			history = append(history, &RolloutRevision{
+				Revision:    revision,
+				ChangeCause: replicaSet.Annotations[changeCauseAnnotation],
+				Name:        replicaSet.Name,
+				Created:     replicaSet.CreationTimestamp.Time,
+				Images:      containerImages(replicaSet.Spec.Template.Spec.Containers),
+				template:    &replicaSet.Spec.Template,
+				annotations: replicaSet.Annotations,
+			})
+		}
+	} else {
+		controllerRevisions, err := k.clientSet.AppsV1().ControllerRevisions(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range controllerRevisions.Items {
+			controllerRevision := &controllerRevisions.Items[i]
+			if !metav1.IsControlledBy(controllerRevision, owner) {
+				continue
+			}
+			history = append(history, &RolloutRevision{
+				Revision:    controllerRevision.Revision,
+				ChangeCause: controllerRevision.Annotations[changeCauseAnnotation],
+				Name:        controllerRevision.Name,
+				Created:     controllerRevision.CreationTimestamp.Time,
+				Images:      controllerRevisionImages(controllerRevision.Data.Raw),
+				data:        controllerRevision.Data.Raw,
+			})
+		}
+	}
+	slices.SortFunc(history, func(a, b *RolloutRevision) int {
+		return cmp.Compare(a.Revision, b.Revision)
+	})
+	return history, nil
+}
+
+func (k *Kubernetes) rolloutPatch(ctx context.Context, namespace, kind, name string, patchType types.PatchType, patch []byte) error {
+	var obj metav1.Object
+	var err error
+	switch kind {
+	case "Deployment":
+		obj, err = k.clientSet.AppsV1().Deployments(namespace).Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+	case "StatefulSet":
+		obj, err = k.clientSet.AppsV1().StatefulSets(namespace).Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+	case "DaemonSet":
+		obj, err = k.clientSet.AppsV1().DaemonSets(namespace).Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	audit.AddObject(ctx, audit.ActionApply, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: kind}, obj)
+	return nil
+}
+
+// rolloutTarget validates the workload kind and resolves the namespace
+func (k *Kubernetes) rolloutTarget(namespace, kind string) (string, error) {
+	if !slices.Contains(RolloutKinds, kind) {
+		return "", fmt.Errorf("unsupported workload kind %s, supported kinds are: %s", kind, strings.Join(RolloutKinds, ", "))
+	}
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: kind}); err != nil {
+		return "", err
+	}
+	return k.namespaceOrDefault(namespace)
+}
+
+func containerImages(containers []v1.Container) []string {
+	images := make([]string, 0, len(containers))
+	for _, container := range containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// controllerRevisionImages returns the container images of the Pod template patch stored in a ControllerRevision
+func controllerRevisionImages(data []byte) []string {
+	revision := struct {
+		Spec struct {
+			Template v1.PodTemplateSpec `json:"template"`
+		} `json:"spec"`
+	}{}
+	if err := json.Unmarshal(data, &revision); err != nil {
+		return nil
+	}
+	return containerImages(revision.Spec.Template.Spec.Containers)
+}
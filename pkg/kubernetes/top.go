@@ -0,0 +1,313 @@
+package kubernetes
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"slices"
+	"strings"
+)
+
+const (
+	TopSortByCPU    = "cpu"
+	TopSortByMemory = "memory"
+
+	metricsGroupVersion = "metrics.k8s.io/v1beta1"
+)
+
+var (
+	podMetricsGVK  = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetrics"}
+	podMetricsGVR  = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+	nodeMetricsGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "NodeMetrics"}
+	nodeMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+
+	errMetricsNotAvailable = errors.New("metrics API (" + metricsGroupVersion + ") is not available, make sure metrics-server is installed and running in the cluster")
+)
+
+// TopOptions are the options to retrieve the resource usage of Pods or Nodes
+type TopOptions struct {
+	// LabelSelector filters the Pods or Nodes (all if empty)
+	LabelSelector string
+	// SortBy sorts the results by descending usage of TopSortByCPU or TopSortByMemory (by name if empty)
+	SortBy string
+}
+
+// ResourceUsage is the CPU and memory usage along with the resource requests and limits
+type ResourceUsage struct {
+	CPU           string `json:"cpu"`
+	Memory        string `json:"memory"`
+	CPURequest    string `json:"cpuRequest,omitempty"`
+	CPULimit      string `json:"cpuLimit,omitempty"`
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	MemoryLimit   string `json:"memoryLimit,omitempty"`
+}
+
+// ContainerUsage is the resource usage of a Pod container
+type ContainerUsage struct {
+	Name string `json:"name"`
+	ResourceUsage
+}
+
+// PodUsage is the resource usage of a Pod (sum of its containers).
+// The Pod limits are only reported if all its containers have limits.
+type PodUsage struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	ResourceUsage
+	Containers []ContainerUsage `json:"containers"`
+
+	cpu    int64
+	memory int64
+}
+
+// NodeUsage is the resource usage of a Node along with its allocatable resources
+type NodeUsage struct {
+	Name              string `json:"name"`
+	CPU               string `json:"cpu"`
+	CPUAllocatable    string `json:"cpuAllocatable,omitempty"`
+	CPUPercent        int64  `json:"cpuPercent"`
+	Memory            string `json:"memory"`
+	MemoryAllocatable string `json:"memoryAllocatable,omitempty"`
+	MemoryPercent     int64  `json:"memoryPercent"`
+
+	cpu    int64
+	memory int64
+}
+
+// PodsTop returns the resource usage of the Pods in the namespace (all namespaces if empty) from the metrics API,
+// joined with the resource requests and limits of their containers.
+// As in resourcesList, the configured namespace is used if the Pods can't be listed in all namespaces.
+func (k *Kubernetes) PodsTop(ctx context.Context, namespace string, options TopOptions) (string, error) {
+	if err := k.checkResourceAllowed(&podMetricsGVK); err != nil {
+		return "", err
+	}
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}); err != nil {
+		return "", err
+	}
+	if err := validateTopSortBy(options.SortBy); err != nil {
+		return "", err
+	}
+	podsGVR := &schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	if namespace == "" && (!k.canIUse(ctx, &podMetricsGVR, namespace, "list") || !k.canIUse(ctx, podsGVR, namespace, "list")) {
+		namespace = k.configuredNamespace()
+	}
+	if namespace != "" {
+		if err := k.checkNamespaceAllowed(namespace); err != nil {
+			return "", err
+		}
+	}
+	metrics, err := k.metricsList(ctx, podMetricsGVR, namespace, options.LabelSelector)
+	if err != nil {
+		return "", err
+	}
+	pods, err := k.clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: options.LabelSelector})
+	if err != nil {
+		return "", err
+	}
+	podSpecs := make(map[string]*v1.PodSpec, len(pods.Items))
+	for i := range pods.Items {
+		if namespace == "" && !k.isNamespaceAllowed(pods.Items[i].Namespace) {
+			continue
+		}
+		podSpecs[pods.Items[i].Namespace+"/"+pods.Items[i].Name] = &pods.Items[i].Spec
+	}
+	usages := make([]*PodUsage, 0, len(metrics.Items))
+	for _, item := range metrics.Items {
+		if namespace == "" && !k.isNamespaceAllowed(item.GetNamespace()) {
+			continue
+		}
+		usages = append(usages, podUsage(item, podSpecs[item.GetNamespace()+"/"+item.GetName()]))
+	}
+	slices.SortFunc(usages, func(a, b *PodUsage) int {
+		return compareUsage(options.SortBy, a.cpu, b.cpu, a.memory, b.memory, a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+	if len(usages) == 0 {
+		return "No pod metrics found", nil
+	}
+	yamlUsages, err := marshal(usages)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("The following pod resource usage (YAML format) was found:\n%s", yamlUsages), nil
+}
+
+// NodesTop returns the resource usage of the Nodes from the metrics API, joined with their allocatable resources
+func (k *Kubernetes) NodesTop(ctx context.Context, options TopOptions) (string, error) {
+	if err := k.checkResourceAllowed(&nodeMetricsGVK); err != nil {
+		return "", err
+	}
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Node"}); err != nil {
+		return "", err
+	}
+	if err := validateTopSortBy(options.SortBy); err != nil {
+		return "", err
+	}
+	metrics, err := k.metricsList(ctx, nodeMetricsGVR, "", options.LabelSelector)
+	if err != nil {
+		return "", err
+	}
+	nodes, err := k.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: options.LabelSelector})
+	if err != nil {
+		return "", err
+	}
+	allocatable := make(map[string]v1.ResourceList, len(nodes.Items))
+	for _, node := range nodes.Items {
+		allocatable[node.Name] = node.Status.Allocatable
+	}
+	usages := make([]*NodeUsage, 0, len(metrics.Items))
+	for _, item := range metrics.Items {
+		usage := usageOf(item.Object)
+		nodeUsage := &NodeUsage{
+			Name:   item.GetName(),
+			CPU:    formatCPU(usage.Cpu().MilliValue()),
+			Memory: formatMemory(usage.Memory().Value()),
+			cpu:    usage.Cpu().MilliValue(),
+			memory: usage.Memory().Value(),
+		}
+		if resources, ok := allocatable[item.GetName()]; ok {
+			if cpu := resources.Cpu().MilliValue(); cpu > 0 {
+				nodeUsage.CPUAllocatable = formatCPU(cpu)
+				nodeUsage.CPUPercent = nodeUsage.cpu * 100 / cpu
+			}
+			if memory := resources.Memory().Value(); memory > 0 {
+				nodeUsage.MemoryAllocatable = formatMemory(memory)
+				nodeUsage.MemoryPercent = nodeUsage.memory * 100 / memory
+			}
+		}
+		usages = append(usages, nodeUsage)
+	}
+	slices.SortFunc(usages, func(a, b *NodeUsage) int {
+		return compareUsage(options.SortBy, a.cpu, b.cpu, a.memory, b.memory, a.Name, b.Name)
+	})
+	if len(usages) == 0 {
+		return "No node metrics found", nil
+	}
+	yamlUsages, err := marshal(usages)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("The following node resource usage (YAML format) was found:\n%s", yamlUsages), nil
+}
+
+// metricsList lists the metrics of the provided resource, or fails with a clear error if the metrics API is not available
+func (k *Kubernetes) metricsList(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string) (*unstructured.UnstructuredList, error) {
+	if !k.supportsGroupVersion(metricsGroupVersion) {
+		return nil, errMetricsNotAvailable
+	}
+	return k.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+}
+
+func podUsage(metrics unstructured.Unstructured, spec *v1.PodSpec) *PodUsage {
+	usage := &PodUsage{Namespace: metrics.GetNamespace(), Name: metrics.GetName(), Containers: make([]ContainerUsage, 0)}
+	containers, _, _ := unstructured.NestedSlice(metrics.Object, "containers")
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		containerUsage := usageOf(container)
+		name, _, _ := unstructured.NestedString(container, "name")
+		usage.cpu += containerUsage.Cpu().MilliValue()
+		usage.memory += containerUsage.Memory().Value()
+		usage.Containers = append(usage.Containers, ContainerUsage{Name: name, ResourceUsage: ResourceUsage{
+			CPU:    formatCPU(containerUsage.Cpu().MilliValue()),
+			Memory: formatMemory(containerUsage.Memory().Value()),
+		}})
+	}
+	usage.CPU = formatCPU(usage.cpu)
+	usage.Memory = formatMemory(usage.memory)
+	if spec == nil {
+		return usage
+	}
+	var cpuRequest, cpuLimit, memoryRequest, memoryLimit int64
+	cpuLimited, memoryLimited := len(spec.Containers) > 0, len(spec.Containers) > 0
+	for _, container := range spec.Containers {
+		cpuRequest += container.Resources.Requests.Cpu().MilliValue()
+		memoryRequest += container.Resources.Requests.Memory().Value()
+		cpuLimited = cpuLimited && !container.Resources.Limits.Cpu().IsZero()
+		cpuLimit += container.Resources.Limits.Cpu().MilliValue()
+		memoryLimited = memoryLimited && !container.Resources.Limits.Memory().IsZero()
+		memoryLimit += container.Resources.Limits.Memory().Value()
+		for i := range usage.Containers {
+			if usage.Containers[i].Name == container.Name {
+				usage.Containers[i].CPURequest = formatCPUIfSet(container.Resources.Requests.Cpu())
+				usage.Containers[i].CPULimit = formatCPUIfSet(container.Resources.Limits.Cpu())
+				usage.Containers[i].MemoryRequest = formatMemoryIfSet(container.Resources.Requests.Memory())
+				usage.Containers[i].MemoryLimit = formatMemoryIfSet(container.Resources.Limits.Memory())
+			}
+		}
+	}
+	if cpuRequest > 0 {
+		usage.CPURequest = formatCPU(cpuRequest)
+	}
+	if cpuLimited {
+		usage.CPULimit = formatCPU(cpuLimit)
+	}
+	if memoryRequest > 0 {
+		usage.MemoryRequest = formatMemory(memoryRequest)
+	}
+	if memoryLimited {
+		usage.MemoryLimit = formatMemory(memoryLimit)
+	}
+	return usage
+}
+
+// usageOf parses the usage field of a metrics object (PodMetrics container or NodeMetrics)
+func usageOf(object map[string]interface{}) v1.ResourceList {
+	usage := v1.ResourceList{}
+	values, _, _ := unstructured.NestedStringMap(object, "usage")
+	for name, value := range values {
+		if quantity, err := resource.ParseQuantity(value); err == nil {
+			usage[v1.ResourceName(name)] = quantity
+		}
+	}
+	return usage
+}
+
+func validateTopSortBy(sortBy string) error {
+	switch sortBy {
+	case "", TopSortByCPU, TopSortByMemory:
+		return nil
+	default:
+		return fmt.Errorf("invalid sort by %s, valid values are: %s, %s", sortBy, TopSortByCPU, TopSortByMemory)
+	}
+}
+
+// compareUsage sorts by descending CPU or memory usage, and then by name
+func compareUsage(sortBy string, cpuA, cpuB, memoryA, memoryB int64, nameA, nameB string) int {
+	switch {
+	case sortBy == TopSortByCPU && cpuA != cpuB:
+		return cmp.Compare(cpuB, cpuA)
+	case sortBy == TopSortByMemory && memoryA != memoryB:
+		return cmp.Compare(memoryB, memoryA)
+	}
+	return strings.Compare(nameA, nameB)
+}
+
+func formatCPU(milliCores int64) string {
+	return fmt.Sprintf("%dm", milliCores)
+}
+
+func formatMemory(bytes int64) string {
+	return fmt.Sprintf("%dMi", bytes/(1024*1024))
+}
+
+func formatCPUIfSet(quantity *resource.Quantity) string {
+	if quantity.IsZero() {
+		return ""
+	}
+	return formatCPU(quantity.MilliValue())
+}
+
+func formatMemoryIfSet(quantity *resource.Quantity) string {
+	if quantity.IsZero() {
+		return ""
+	}
+	return formatMemory(quantity.Value())
+}
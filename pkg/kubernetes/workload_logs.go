@@ -0,0 +1,186 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WorkloadLogsMaxConcurrency is the maximum number of container logs retrieved concurrently
+const WorkloadLogsMaxConcurrency = 5
+
+// WorkloadKinds are the workload kinds whose Pods can be resolved by WorkloadLogs
+var WorkloadKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "Job"}
+
+type containerLogLine struct {
+	timestamp time.Time
+	prefix    string
+	message   string
+}
+
+// WorkloadLogs returns the logs of all the containers of the Pods owned by the provided workload (or matching the label selector),
+// interleaved by timestamp and prefixed with the Pod and container names
+func (k *Kubernetes) WorkloadLogs(ctx context.Context, namespace, kind, name, labelSelector string, options PodsLogOptions) (string, error) {
+	if err := k.checkResourceAllowed(&schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}); err != nil {
+		return "", err
+	}
+	namespace, err := k.namespaceOrDefault(namespace)
+	if err != nil {
+		return "", err
+	}
+	selector, err := k.workloadSelector(ctx, namespace, kind, name, labelSelector)
+	if err != nil {
+		return "", err
+	}
+	pods, err := k.clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return "", err
+	}
+	if len(pods.Items) == 0 {
+		return "", nil
+	}
+	// Timestamps are always retrieved to interleave the lines, they are removed afterward if not requested
+	containerOptions := options
+	containerOptions.Timestamps = true
+	podLogOptions, err := containerOptions.podLogOptions()
+	if err != nil {
+		return "", err
+	}
+	var (
+		mutex     sync.Mutex
+		wg        sync.WaitGroup
+		lines     []containerLogLine
+		failures  []string
+		semaphore = make(chan struct{}, WorkloadLogsMaxConcurrency)
+	)
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			wg.Add(1)
+			go func(pod, container string) {
+				defer wg.Done()
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+				containerLogOptions := *podLogOptions
+				containerLogOptions.Container = container
+				containerLines, err := k.containerLogLines(ctx, namespace, pod, &containerLogOptions, options)
+				mutex.Lock()
+				defer mutex.Unlock()
+				if err != nil {
+					failures = append(failures, fmt.Sprintf("# Failed to get the logs of %s/%s: %v\n", pod, container, err))
+					return
+				}
+				lines = append(lines, containerLines...)
+			}(pod.Name, container.Name)
+		}
+	}
+	wg.Wait()
+	slices.SortStableFunc(lines, func(a, b containerLogLine) int {
+		return a.timestamp.Compare(b.timestamp)
+	})
+	formatted := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if options.Timestamps && !line.timestamp.IsZero() {
+			formatted = append(formatted, line.prefix+line.timestamp.Format(time.RFC3339Nano)+" "+line.message)
+		} else {
+			formatted = append(formatted, line.prefix+line.message)
+		}
+	}
+	slices.Sort(failures)
+	log, err := readLog(strings.NewReader(strings.Join(formatted, "")), nil, false, PodsLogMaxBytes)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(failures, "") + log, nil
+}
+
+// containerLogLines retrieves the log lines (with timestamps) of a Pod container prefixed with the Pod and container names
+func (k *Kubernetes) containerLogLines(ctx context.Context, namespace, pod string, podLogOptions *v1.PodLogOptions, options PodsLogOptions) ([]containerLogLine, error) {
+	stream, err := k.clientSet.CoreV1().Pods(namespace).GetLogs(pod, podLogOptions).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = stream.Close() }()
+	rawLines, _, err := readLogLines(stream, options.Filter, true, PodsLogMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	prefix := "[" + pod + "/" + podLogOptions.Container + "] "
+	lines := make([]containerLogLine, 0, len(rawLines))
+	for _, rawLine := range rawLines {
+		timestamp, message := splitLogTimestamp(rawLine)
+		lines = append(lines, containerLogLine{timestamp: timestamp, prefix: prefix, message: message})
+	}
+	return lines, nil
+}
+
+// workloadSelector returns the Pod selector of the provided workload, or the parsed label selector if no workload is provided.
+// If both are provided, the Pods must match the workload selector and the label selector.
+func (k *Kubernetes) workloadSelector(ctx context.Context, namespace, kind, name, labelSelector string) (labels.Selector, error) {
+	if kind == "" && name == "" {
+		if labelSelector == "" {
+			return nil, errors.New("either a workload kind and name or a label selector must be provided")
+		}
+		return labels.Parse(labelSelector)
+	}
+	if kind == "" || name == "" {
+		return nil, errors.New("both workload kind and name must be provided")
+	}
+	if !slices.Contains(WorkloadKinds, kind) {
+		return nil, fmt.Errorf("unsupported workload kind %s, supported kinds are: %s", kind, strings.Join(WorkloadKinds, ", "))
+	}
+	gvk := &schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: kind}
+	if kind == "Job" {
+		gvk.Group = "batch"
+	}
+	if err := k.checkResourceAllowed(gvk); err != nil {
+		return nil, err
+	}
+	var selector *metav1.LabelSelector
+	switch kind {
+	case "Deployment":
+		deployment, err := k.clientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = deployment.Spec.Selector
+	case "StatefulSet":
+		statefulSet, err := k.clientSet.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = statefulSet.Spec.Selector
+	case "DaemonSet":
+		daemonSet, err := k.clientSet.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = daemonSet.Spec.Selector
+	case "Job":
+		job, err := k.clientSet.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = job.Spec.Selector
+	}
+	if selector == nil {
+		return nil, fmt.Errorf("%s %s has no Pod selector", kind, name)
+	}
+	workloadSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil || labelSelector == "" {
+		return workloadSelector, err
+	}
+	additionalSelector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	requirements, _ := additionalSelector.Requirements()
+	return workloadSelector.Add(requirements...), nil
+}
@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/klog/v2"
+	"sync"
+)
+
+const methodNotificationCancelled = "notifications/cancelled"
+
+// cancellations tracks the long-running tool calls (e.g. pods_log with follow) that the client can cancel with a
+// notifications/cancelled message.
+// mcp-go doesn't cancel the context of the tool handlers and the request id is only available to the hooks,
+// so the cancellable tool calls are correlated with their request id through the request progress token.
+type cancellations struct {
+	mu sync.Mutex
+	// progressTokens of the in-flight tool calls keyed by session and request id
+	progressTokens map[string]string
+	// cancel functions of the cancellable tool calls keyed by session and progress token
+	cancels map[string]context.CancelFunc
+}
+
+func newCancellations() *cancellations {
+	return &cancellations{
+		progressTokens: make(map[string]string),
+		cancels:        make(map[string]context.CancelFunc),
+	}
+}
+
+// addHooks tracks the request ids of the in-flight tool calls with a progress token
+func (c *cancellations) addHooks(hooks *server.Hooks) {
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest) {
+		if token := progressToken(*message); token != nil {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.progressTokens[cancellationKey(ctx, id)] = cancellationKey(ctx, token)
+		}
+	})
+	hooks.AddAfterCallTool(func(ctx context.Context, id any, _ *mcp.CallToolRequest, _ *mcp.CallToolResult) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.progressTokens, cancellationKey(ctx, id))
+	})
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, _ any, _ error) {
+		if method == mcp.MethodToolsCall {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			delete(c.progressTokens, cancellationKey(ctx, id))
+		}
+	})
+}
+
+// cancellable returns a copy of the context that is cancelled if the client cancels the tool call.
+// The returned function must be called when the tool call completes.
+func (c *cancellations) cancellable(ctx context.Context, ctr mcp.CallToolRequest) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	token := progressToken(ctr)
+	if token == nil {
+		return ctx, cancel
+	}
+	key := cancellationKey(ctx, token)
+	c.mu.Lock()
+	c.cancels[key] = cancel
+	c.mu.Unlock()
+	return ctx, func() {
+		c.mu.Lock()
+		delete(c.cancels, key)
+		c.mu.Unlock()
+		cancel()
+	}
+}
+
+// onCancelled handles the notifications/cancelled messages sent by the client
+func (c *cancellations) onCancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	requestID := notification.Params.AdditionalFields["requestId"]
+	c.mu.Lock()
+	cancel, found := c.cancels[c.progressTokens[cancellationKey(ctx, requestID)]]
+	c.mu.Unlock()
+	if found {
+		klog.V(1).InfoS("Tool call cancelled by the client", "sessionId", sessionID(ctx),
+			"requestId", requestID, "reason", notification.Params.AdditionalFields["reason"])
+		cancel()
+	}
+}
+
+// progressToken returns the progress token of the tool call request, or nil if the client didn't provide one
+func progressToken(ctr mcp.CallToolRequest) mcp.ProgressToken {
+	if ctr.Params.Meta == nil {
+		return nil
+	}
+	return ctr.Params.Meta.ProgressToken
+}
+
+func cancellationKey(ctx context.Context, id any) string {
+	return sessionID(ctx) + "/" + fmt.Sprint(id)
+}
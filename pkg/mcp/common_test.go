@@ -92,12 +92,18 @@ func TestMain(m *testing.M) {
 }
 
 type mcpContext struct {
-	ctx           context.Context
-	tempDir       string
-	cancel        context.CancelFunc
-	mcpServer     *Server
-	mcpHttpServer *httptest.Server
-	mcpClient     *client.SSEMCPClient
+	readOnly          bool
+	enabledTools      []string
+	disabledTools     []string
+	deniedResources   []string
+	allowedNamespaces []string
+	identityMode      string
+	ctx               context.Context
+	tempDir           string
+	cancel            context.CancelFunc
+	mcpServer         *Server
+	mcpHttpServer     *httptest.Server
+	mcpClient         *client.Client
 }
 
 func (c *mcpContext) beforeEach(t *testing.T) {
@@ -105,7 +111,14 @@ func (c *mcpContext) beforeEach(t *testing.T) {
 	c.ctx, c.cancel = context.WithCancel(context.Background())
 	c.tempDir = t.TempDir()
 	c.withKubeConfig(nil)
-	if c.mcpServer, err = NewSever(); err != nil {
+	if c.mcpServer, err = NewSever(Configuration{
+		ReadOnly:          c.readOnly,
+		EnabledTools:      c.enabledTools,
+		DisabledTools:     c.disabledTools,
+		DeniedResources:   c.deniedResources,
+		AllowedNamespaces: c.allowedNamespaces,
+		IdentityMode:      c.identityMode,
+	}); err != nil {
 		t.Fatal(err)
 		return
 	}
@@ -136,7 +149,10 @@ func (c *mcpContext) afterEach() {
 }
 
 func testCase(t *testing.T, test func(c *mcpContext)) {
-	mcpCtx := &mcpContext{}
+	testCaseWithContext(t, &mcpContext{}, test)
+}
+
+func testCaseWithContext(t *testing.T, mcpCtx *mcpContext, test func(c *mcpContext)) {
 	mcpCtx.beforeEach(t)
 	defer mcpCtx.afterEach()
 	test(mcpCtx)
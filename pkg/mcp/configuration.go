@@ -3,32 +3,49 @@ package mcp
 import (
 	"context"
 	"fmt"
-	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 func (s *Server) initConfiguration() []server.ServerTool {
 	return []server.ServerTool{
-		{mcp.NewTool("configuration_view",
+		{Tool: mcp.NewTool("configuration_view",
 			mcp.WithDescription("Get the current Kubernetes configuration content as a kubeconfig YAML"),
 			mcp.WithBoolean("minified", mcp.Description("Return a minified version of the configuration. "+
 				"If set to true, keeps only the current-context and the relevant pieces of the configuration for that context. "+
 				"If set to false, all contexts, clusters, auth-infos, and users are returned in the configuration. "+
 				"(Optional, default true)")),
-		), configurationView},
+			mcp.WithTitleAnnotation("Configuration: View"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.configurationView},
+		{Tool: mcp.NewTool("contexts_list",
+			mcp.WithDescription("List the Kubernetes contexts available in the kubeconfig, including their cluster, server and default namespace. "+
+				"Any of the provided context names can be used as the context argument of the rest of the tools"),
+			mcp.WithTitleAnnotation("Contexts: List"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.contextsList},
 	}
 }
 
-func configurationView(_ context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) configurationView(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	minify := true
-	minified := ctr.Params.Arguments["minified"]
+	minified := ctr.GetArguments()["minified"]
 	if _, ok := minified.(bool); ok {
 		minify = minified.(bool)
 	}
-	ret, err := kubernetes.ConfigurationView(minify)
+	ret, err := s.k.Load().ConfigurationView(minify)
 	if err != nil {
-		err = fmt.Errorf("failed to get configuration: %v", err)
+		err = fmt.Errorf("failed to get configuration: %w", err)
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
+}
+
+func (s *Server) contextsList(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ret, err := s.k.Load().ContextsList()
+	if err != nil {
+		err = fmt.Errorf("failed to list contexts: %w", err)
+	}
+	return NewTextResult(ctx, ret, err), nil
 }
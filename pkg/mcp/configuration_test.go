@@ -6,6 +6,7 @@ import (
 	"k8s.io/client-go/rest"
 	v1 "k8s.io/client-go/tools/clientcmd/api/v1"
 	"sigs.k8s.io/yaml"
+	"strings"
 	"testing"
 )
 
@@ -176,3 +177,105 @@ func TestConfigurationViewInCluster(t *testing.T) {
 		})
 	})
 }
+
+func TestContextsList(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		toolResult, err := c.callTool("contexts_list", map[string]interface{}{})
+		t.Run("contexts_list returns contexts", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("call tool failed %v", err)
+			}
+			if toolResult.IsError {
+				t.Fatalf("call tool failed")
+			}
+		})
+		var decoded []map[string]interface{}
+		err = yaml.Unmarshal([]byte(strings.SplitN(toolResult.Content[0].(mcp.TextContent).Text, "\n", 2)[1]), &decoded)
+		t.Run("contexts_list has yaml content", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("invalid tool result content %v", err)
+			}
+		})
+		t.Run("contexts_list returns all contexts", func(t *testing.T) {
+			if len(decoded) != 2 {
+				t.Fatalf("invalid context count, expected 2, got %v", len(decoded))
+			}
+			if decoded[0]["name"] != "additional-context" {
+				t.Errorf("additional-context not found: %v", decoded)
+			}
+			if decoded[1]["name"] != "fake-context" {
+				t.Errorf("fake-context not found: %v", decoded)
+			}
+		})
+		t.Run("contexts_list returns cluster and default namespace info", func(t *testing.T) {
+			if decoded[1]["cluster"] != "fake" {
+				t.Errorf("fake cluster not found: %v", decoded[1])
+			}
+			if decoded[1]["server"] != "https://example.com" {
+				t.Errorf("fake server not found: %v", decoded[1])
+			}
+			if decoded[1]["namespace"] != "default" {
+				t.Errorf("default namespace not found: %v", decoded[1])
+			}
+		})
+		t.Run("contexts_list flags current context", func(t *testing.T) {
+			if decoded[0]["current"] != false {
+				t.Errorf("additional-context should not be current: %v", decoded[0])
+			}
+			if decoded[1]["current"] != true {
+				t.Errorf("fake-context should be current: %v", decoded[1])
+			}
+		})
+	})
+}
+
+func TestToolsWithContext(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		c.withEnvTest()
+		t.Run("pods_list with existing context returns pods list", func(t *testing.T) {
+			toolResult, err := c.callTool("pods_list", map[string]interface{}{
+				"context": "fake-context",
+			})
+			if err != nil {
+				t.Fatalf("call tool failed %v", err)
+			}
+			if toolResult.IsError {
+				t.Fatalf("call tool failed %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("pods_list with non-existent context returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("pods_list", map[string]interface{}{
+				"context": "non-existent-context",
+			})
+			if toolResult.IsError != true {
+				t.Fatalf("call tool should fail")
+			}
+			if !strings.HasPrefix(toolResult.Content[0].(mcp.TextContent).Text, "failed to resolve context non-existent-context") {
+				t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("pods_list with context reuses pooled client", func(t *testing.T) {
+			first, _ := c.mcpServer.pool.Get("fake-context")
+			second, _ := c.mcpServer.pool.Get("fake-context")
+			if first != second {
+				t.Fatalf("expected pooled client to be reused")
+			}
+		})
+	})
+}
+
+func TestToolsWithInvalidContext(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		toolResult, err := c.callTool("namespaces_list", map[string]interface{}{
+			"context": 1,
+		})
+		t.Run("namespaces_list with non-string context returns error", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("call tool failed %v", err)
+			}
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != "invalid context argument 1, must be a string" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+	})
+}
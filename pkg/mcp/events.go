@@ -9,22 +9,30 @@ import (
 
 func (s *Server) initEvents() []server.ServerTool {
 	return []server.ServerTool{
-		{mcp.NewTool("events_list",
+		{Tool: mcp.NewTool("events_list",
 			mcp.WithDescription("List all the Kubernetes events in the current cluster from all namespaces"),
 			mcp.WithString("namespace",
 				mcp.Description("Optional Namespace to retrieve the events from. If not provided, will list events from all namespaces")),
-		), s.eventsList},
+			withContext(),
+			mcp.WithTitleAnnotation("Events: List"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.eventsList},
 	}
 }
 
 func (s *Server) eventsList(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace := ctr.Params.Arguments["namespace"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	namespace := ctr.GetArguments()["namespace"]
 	if namespace == nil {
 		namespace = ""
 	}
-	ret, err := s.k.EventsList(ctx, namespace.(string))
+	ret, err := k.EventsList(ctx, namespace.(string))
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to list events in all namespaces: %v", err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to list events in all namespaces: %w", err)), nil
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
 }
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
+	"github.com/manusa/kubernetes-mcp-server/pkg/auth"
+	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/manusa/kubernetes-mcp-server/pkg/metrics"
+	"github.com/manusa/kubernetes-mcp-server/pkg/tracing"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"time"
+)
+
+// toolCall holds the error of the failed result of a tool call, it's placed in the call context by instrumented and filled by NewTextResult
+type toolCall struct {
+	err error
+}
+
+type toolCallContextKey struct{}
+
+// recordToolError stores the error of a failed tool result in the tool call of the context (if any)
+func recordToolError(ctx context.Context, err error) {
+	if call, ok := ctx.Value(toolCallContextKey{}).(*toolCall); ok {
+		call.err = err
+	}
+}
+
+// instrumented wraps the tool handler to trace the call and record the call count, latency and error reason metrics
+func instrumented(tool server.ServerTool) server.ServerTool {
+	name, handler := tool.Tool.Name, tool.Handler
+	tool.Handler = func(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		ctx, span := tracing.Tracer().Start(ctx, "tools/call "+name, trace.WithAttributes(
+			attribute.String("mcp.tool.name", name),
+			attribute.String("mcp.tool.arguments", spanArguments(ctr.GetArguments())),
+		))
+		defer span.End()
+		var record *audit.Record
+		if audit.Enabled() {
+			record = auditRecord(ctx, name, ctr.GetArguments())
+			ctx = audit.WithRecord(ctx, record)
+		}
+		call := &toolCall{}
+		result, err := handler(context.WithValue(ctx, toolCallContextKey{}, call), ctr)
+		toolErr := err
+		if toolErr == nil && result != nil && result.IsError {
+			toolErr = call.err
+		}
+		duration := time.Since(start)
+		reason := errorReason(result, toolErr)
+		if reason != "" {
+			span.SetAttributes(attribute.String("mcp.tool.error_reason", reason))
+			if toolErr != nil {
+				span.RecordError(toolErr)
+			}
+			span.SetStatus(codes.Error, reason)
+		}
+		metrics.ObserveToolCall(name, duration, reason)
+		if reason != "" {
+			klog.V(1).ErrorS(toolErr, "Tool call failed", "tool", name, "reason", reason, "sessionId", sessionID(ctx), "duration", duration)
+		} else {
+			klog.V(2).InfoS("Tool call succeeded", "tool", name, "sessionId", sessionID(ctx), "duration", duration)
+		}
+		if record != nil {
+			record.Duration = duration.Seconds()
+			record.Outcome = audit.OutcomeSuccess
+			if reason != "" {
+				record.Outcome, record.Reason = audit.OutcomeError, reason
+			}
+			if toolErr != nil {
+				record.Error = toolErr.Error()
+			}
+			if auditErr := audit.Log(record); auditErr != nil {
+				klog.ErrorS(auditErr, "Failed to write audit log record", "tool", name, "sessionId", record.SessionID)
+			}
+		}
+		return result, err
+	}
+	return tool
+}
+
+// auditRecord returns the audit log record of a tool call with the session, authenticated user, and sanitized arguments
+func auditRecord(ctx context.Context, tool string, arguments map[string]any) *audit.Record {
+	record := &audit.Record{Timestamp: time.Now().UTC(), SessionID: sessionID(ctx), Tool: tool, Arguments: sanitizeArguments(arguments)}
+	if user := auth.UserFrom(ctx); user != nil {
+		record.User, record.Groups = user.Name, user.Groups
+	}
+	if namespace, ok := arguments["namespace"].(string); ok {
+		record.Namespace = namespace
+	}
+	return record
+}
+
+// spanArguments returns the sanitized tool call arguments in JSON format
+func spanArguments(arguments map[string]any) string {
+	if len(arguments) == 0 {
+		return "{}"
+	}
+	ret, err := json.Marshal(sanitizeArguments(arguments))
+	if err != nil {
+		return redacted
+	}
+	return string(ret)
+}
+
+// errorReason returns the reason of the tool call failure (Kubernetes status reason or server policy), or empty if it succeeded
+func errorReason(result *mcp.CallToolResult, err error) string {
+	if err == nil && (result == nil || !result.IsError) {
+		return ""
+	}
+	switch {
+	case err == nil:
+		// metav1.StatusReasonUnknown is empty, which would report the failed result as a success
+		return "Unknown"
+	case errors.Is(err, kubernetes.ErrReadOnly):
+		return "ReadOnly"
+	case errors.Is(err, kubernetes.ErrResourceDenied):
+		return "ResourceDenied"
+	case errors.Is(err, kubernetes.ErrNamespaceDenied):
+		return "NamespaceDenied"
+	case errors.Is(err, context.DeadlineExceeded):
+		return string(metav1.StatusReasonTimeout)
+	}
+	if reason := apierrors.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+		return string(reason)
+	}
+	return "Unknown"
+}
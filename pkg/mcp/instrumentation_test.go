@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
+	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestErrorReason(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{
+		{nil, ""},
+		{apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "a-pod"), "NotFound"},
+		{fmt.Errorf("failed to get pod: %w", apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "a-pod", errors.New("denied"))), "Forbidden"},
+		{fmt.Errorf("failed to delete pod: %w", kubernetes.ErrReadOnly), "ReadOnly"},
+		{fmt.Errorf("failed to get secret: %w", kubernetes.ErrResourceDenied), "ResourceDenied"},
+		{fmt.Errorf("failed to list pods: %w", kubernetes.ErrNamespaceDenied), "NamespaceDenied"},
+		{errors.New("something went wrong"), "Unknown"},
+	} {
+		t.Run(fmt.Sprintf("errorReason for %v returns %q", tc.err, tc.expected), func(t *testing.T) {
+			if reason := errorReason(NewTextResult(context.Background(), "", tc.err), tc.err); reason != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, reason)
+			}
+		})
+	}
+	t.Run("errorReason for failed result without error returns Unknown", func(t *testing.T) {
+		if reason := errorReason(&mcp.CallToolResult{IsError: true}, nil); reason != "Unknown" {
+			t.Fatalf("expected Unknown, got %q", reason)
+		}
+	})
+}
+
+func TestInstrumentedToolError(t *testing.T) {
+	auditLogPath := filepath.Join(t.TempDir(), "audit.log")
+	closeAuditLog, err := audit.Init(audit.Options{Path: auditLogPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closeAuditLog() }()
+	lastRecord := func() map[string]any {
+		auditLog, _ := os.ReadFile(auditLogPath)
+		lines := strings.Split(strings.TrimSpace(string(auditLog)), "\n")
+		record := map[string]any{}
+		_ = json.Unmarshal([]byte(lines[len(lines)-1]), &record)
+		return record
+	}
+	failing := instrumented(server.ServerTool{Tool: mcp.NewTool("failing"), Handler: func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to delete pod: %w", kubernetes.ErrReadOnly)), nil
+	}})
+	t.Run("instrumented handler with failed result records the error reason", func(t *testing.T) {
+		result, err := failing.Handler(context.Background(), mcp.CallToolRequest{})
+		if err != nil || !result.IsError {
+			t.Fatalf("expected failed result, got %v %v", result, err)
+		}
+		if record := lastRecord(); record["outcome"] != "error" || record["reason"] != "ReadOnly" || record["error"] != "failed to delete pod: "+kubernetes.ErrReadOnly.Error() {
+			t.Fatalf("invalid audit log record, got %v", record)
+		}
+	})
+	withoutError := instrumented(server.ServerTool{Tool: mcp.NewTool("without-error"), Handler: func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("failed without error"), nil
+	}})
+	t.Run("instrumented handler with failed result without error records the Unknown reason", func(t *testing.T) {
+		if result, err := withoutError.Handler(context.Background(), mcp.CallToolRequest{}); err != nil || !result.IsError {
+			t.Fatalf("expected failed result, got %v %v", result, err)
+		}
+		if record := lastRecord(); record["outcome"] != "error" || record["reason"] != "Unknown" {
+			t.Fatalf("invalid audit log record, got %v", record)
+		}
+	})
+	recovering := instrumented(server.ServerTool{Tool: mcp.NewTool("recovering"), Handler: func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		_ = NewTextResult(ctx, "", errors.New("discarded"))
+		return NewTextResult(ctx, "ok", nil), nil
+	}})
+	t.Run("instrumented handler with discarded failed result records success", func(t *testing.T) {
+		if result, err := recovering.Handler(context.Background(), mcp.CallToolRequest{}); err != nil || result.IsError {
+			t.Fatalf("expected successful result, got %v %v", result, err)
+		}
+		if record := lastRecord(); record["outcome"] != "success" || record["error"] != nil {
+			t.Fatalf("invalid audit log record, got %v", record)
+		}
+	})
+}
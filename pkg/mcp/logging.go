@@ -0,0 +1,33 @@
+package mcp
+
+import (
+	"context"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/klog/v2"
+)
+
+// sessionLoggingHooks logs the lifecycle of the MCP client sessions
+func sessionLoggingHooks() *server.Hooks {
+	hooks := &server.Hooks{}
+	hooks.AddOnRegisterSession(func(_ context.Context, session server.ClientSession) {
+		klog.V(1).InfoS("MCP session registered", "sessionId", session.SessionID())
+	})
+	hooks.AddAfterInitialize(func(ctx context.Context, _ any, message *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		klog.V(1).InfoS("MCP session initialized", "sessionId", sessionID(ctx),
+			"clientName", message.Params.ClientInfo.Name, "clientVersion", message.Params.ClientInfo.Version,
+			"protocolVersion", result.ProtocolVersion)
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		klog.V(1).InfoS("MCP session unregistered", "sessionId", session.SessionID())
+	})
+	return hooks
+}
+
+// sessionID returns the id of the MCP client session of the context, or empty if there is none
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
@@ -1,52 +1,227 @@
 package mcp
 
 import (
+	"context"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
+	"github.com/manusa/kubernetes-mcp-server/pkg/auth"
 	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/manusa/kubernetes-mcp-server/pkg/metrics"
 	"github.com/manusa/kubernetes-mcp-server/pkg/version"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/klog/v2"
+	"math"
 	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+type Configuration struct {
+	// Kubeconfig is the path to the kubeconfig file (KUBECONFIG or ~/.kube/config if empty)
+	Kubeconfig string
+	// When true, only tools annotated as read-only are exposed and the Kubernetes clients reject any write
+	ReadOnly bool
+	// EnabledTools is the list of tools to expose (all tools if empty)
+	EnabledTools []string
+	// DisabledTools is the list of tools to hide, applied after EnabledTools
+	DisabledTools []string
+	// DeniedResources is the list of resource kinds that can't be accessed in "<apiVersion> <kind>" format (e.g. "v1 Secret")
+	DeniedResources []string
+	// AllowedNamespaces is the list of namespace names or glob patterns that can be accessed (all namespaces if empty)
+	AllowedNamespaces []string
+	// IdentityMode is the identity used for the requests of authenticated users: server (default), impersonate, or passthrough
+	IdentityMode string
+}
+
+// toolNames are the names of all the tools the server provides, including the ones that depend on the cluster type
+var toolNames = []string{
+	"configuration_view",
+	"contexts_list",
+	"events_list",
+	"namespaces_list",
+	"projects_list",
+	"nodes_top",
+	"pods_list",
+	"pods_list_in_namespace",
+	"pods_get",
+	"pods_delete",
+	"pods_exec",
+	"pods_debug",
+	"pods_cp_from",
+	"pods_cp_to",
+	"pods_log",
+	"pods_top",
+	"pods_run",
+	"pods_port_forward_start",
+	"pods_port_forward_list",
+	"pods_port_forward_stop",
+	"http_get",
+	"resources_list",
+	"resources_get",
+	"resources_create_or_update",
+	"resources_delete",
+	"resources_scale",
+	"rollout_status",
+	"rollout_history",
+	"rollout_restart",
+	"rollout_undo",
+	"workload_logs",
+}
+
 type Server struct {
-	server *server.MCPServer
-	k      *kubernetes.Kubernetes
+	// configuration and k are replaced by the configuration and kubeconfig reloads while the tool handlers read them
+	configuration atomic.Pointer[Configuration]
+	server        *server.MCPServer
+	cancellations *cancellations
+	portForwards  *portForwards
+	pool          *kubernetes.Pool
+	k             atomic.Pointer[kubernetes.Kubernetes]
+	// reloadErr is the error of the last failed reload (nil if it succeeded), the previous clients and tools are kept in use
+	reloadErr atomic.Pointer[error]
+	// reloadMutex serializes the configuration and kubeconfig reloads
+	reloadMutex sync.Mutex
 }
 
-func NewSever() (*Server, error) {
+func NewSever(configuration Configuration) (*Server, error) {
+	metrics.Register()
+	kubernetesOptions, err := configuration.kubernetesOptions()
+	if err != nil {
+		return nil, err
+	}
 	s := &Server{
-		server: server.NewMCPServer(
-			version.BinaryName,
-			version.Version,
-			server.WithResourceCapabilities(true, true),
-			server.WithPromptCapabilities(true),
-			server.WithToolCapabilities(true),
-			server.WithLogging(),
-		),
+		cancellations: newCancellations(),
+		portForwards:  newPortForwards(),
+		pool:          kubernetes.NewPool(kubernetesOptions),
 	}
+	s.configuration.Store(&configuration)
+	hooks := sessionLoggingHooks()
+	s.cancellations.addHooks(hooks)
+	s.portForwards.addHooks(hooks)
+	s.server = server.NewMCPServer(
+		version.BinaryName,
+		version.Version,
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(true),
+		server.WithToolCapabilities(true),
+		server.WithLogging(),
+		server.WithHooks(hooks),
+	)
+	s.server.AddNotificationHandler(methodNotificationCancelled, s.cancellations.onCancelled)
 	if err := s.reloadKubernetesClient(); err != nil {
 		return nil, err
 	}
-	s.k.WatchKubeConfig(s.reloadKubernetesClient)
+	s.pool.WatchKubeConfig(s.onKubeConfigChange)
 	return s, nil
 }
 
+// SetConfiguration applies the provided configuration to the running server and notifies the clients of any tool change.
+// If the configuration is invalid, the server keeps running with the previous one.
+func (s *Server) SetConfiguration(configuration Configuration) error {
+	kubernetesOptions, err := configuration.kubernetesOptions()
+	if err != nil {
+		return err
+	}
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+	previous := s.configuration.Swap(&configuration)
+	kubernetesOptionsChanged := s.pool.SetOptions(kubernetesOptions)
+	err = s.reloadKubernetesClient()
+	if err != nil {
+		s.configuration.Store(previous)
+		previousKubernetesOptions, _ := previous.kubernetesOptions()
+		kubernetesOptionsChanged = s.pool.SetOptions(previousKubernetesOptions) || kubernetesOptionsChanged
+		_ = s.reloadKubernetesClient()
+	}
+	if kubernetesOptionsChanged {
+		s.pool.WatchKubeConfig(s.onKubeConfigChange)
+	}
+	return err
+}
+
 func (s *Server) reloadKubernetesClient() error {
-	k, err := kubernetes.NewKubernetes()
+	// The previous clients and tools are kept until the new ones are successfully built
+	err := s.buildKubernetesClient()
+	if err != nil {
+		s.reloadErr.Store(&err)
+	} else {
+		s.reloadErr.Store(nil)
+	}
+	return err
+}
+
+func (s *Server) buildKubernetesClient() error {
+	configuration := s.configuration.Load()
+	if err := configuration.validateTools(); err != nil {
+		return err
+	}
+	s.pool.Invalidate()
+	k, err := s.pool.Get("")
 	if err != nil {
 		return err
 	}
-	s.k = k
-	s.server.SetTools(slices.Concat(
+	s.k.Store(k)
+	applicableTools := configuration.applicableTools(slices.Concat(
 		s.initConfiguration(),
 		s.initEvents(),
 		s.initNamespaces(),
+		s.initNodes(),
 		s.initPods(),
+		s.initPortForward(),
 		s.initResources(),
-	)...)
+		s.initWorkloads(),
+	))
+	for i := range applicableTools {
+		applicableTools[i] = instrumented(applicableTools[i])
+	}
+	s.server.SetTools(applicableTools...)
+	return nil
+}
+
+// onKubeConfigChange reloads the Kubernetes clients whenever the kubeconfig files change
+func (s *Server) onKubeConfigChange() error {
+	klog.V(1).InfoS("Kubeconfig changed, reloading Kubernetes client")
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+	if err := s.reloadKubernetesClient(); err != nil {
+		klog.ErrorS(err, "Failed to reload Kubernetes client after kubeconfig change, keeping the previous client and tools")
+		return err
+	}
+	klog.V(1).InfoS("Kubernetes client reloaded", "host", s.k.Load().Host())
 	return nil
 }
 
+// Ready returns an error if the server can't serve requests with the current configuration:
+// the last reload of the Kubernetes clients failed (the previous clients are still in use), or the Kubernetes API server is not reachable.
+func (s *Server) Ready(ctx context.Context) error {
+	if reloadErr := s.reloadErr.Load(); reloadErr != nil {
+		return fmt.Errorf("failed to reload kubernetes client, the previous client is still in use: %v", *reloadErr)
+	}
+	if err := s.k.Load().Ping(ctx); err != nil {
+		return fmt.Errorf("kubernetes API server is not reachable: %v", err)
+	}
+	return nil
+}
+
+// applicableTools filters the provided tools according to the read-only, enabled and disabled tools configuration
+func (c *Configuration) applicableTools(tools []server.ServerTool) []server.ServerTool {
+	applicableTools := make([]server.ServerTool, 0)
+	for _, tool := range tools {
+		if c.ReadOnly && !isReadOnly(tool.Tool) {
+			continue
+		}
+		if len(c.EnabledTools) > 0 && !slices.Contains(c.EnabledTools, tool.Tool.Name) {
+			continue
+		}
+		if slices.Contains(c.DisabledTools, tool.Tool.Name) {
+			continue
+		}
+		applicableTools = append(applicableTools, tool)
+	}
+	return applicableTools
+}
+
 func (s *Server) ServeStdio() error {
 	return server.ServeStdio(s.server)
 }
@@ -57,18 +232,120 @@ func (s *Server) ServeSse(baseUrl string) *server.SSEServer {
 		options = append(options, server.WithBaseURL(baseUrl))
 		options = append(options, server.WithKeepAlive(true))
 	}
-	return server.NewSSEServer(s.server, options...).
+	return server.NewSSEServer(s.server, options...)
+}
+
+// ServeHTTP returns a Streamable HTTP transport handler for the MCP server.
+// Sessions are stateful, clients must provide the Mcp-Session-Id header returned on initialization.
+func (s *Server) ServeHTTP() *server.StreamableHTTPServer {
+	return server.NewStreamableHTTPServer(s.server)
 }
 
 func (s *Server) Close() {
-	if s.k != nil {
-		s.k.Close()
+	if s.portForwards != nil {
+		s.portForwards.stopAll()
+	}
+	if s.pool != nil {
+		s.pool.Close()
+	}
+}
+
+// kubernetesFor returns the Kubernetes client for the context provided in the tool call arguments (if any).
+// If the request was authenticated, the client performs the requests on behalf of the user (depending on the identity mode).
+func (s *Server) kubernetesFor(ctx context.Context, ctr mcp.CallToolRequest) (*kubernetes.Kubernetes, error) {
+	k := s.k.Load()
+	if kubeContext := ctr.GetArguments()["context"]; kubeContext != nil && kubeContext != "" {
+		kubeContextName, ok := kubeContext.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid context argument %v, must be a string", kubeContext)
+		}
+		var err error
+		if k, err = s.pool.Get(kubeContextName); err != nil {
+			return nil, fmt.Errorf("failed to resolve context %s: %w", kubeContextName, err)
+		}
+	}
+	derived, err := k.Derived(auth.UserFrom(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for the authenticated user: %w", err)
+	}
+	audit.SetCluster(ctx, derived.Host())
+	return derived, nil
+}
+
+func (c *Configuration) kubernetesOptions() (kubernetes.Options, error) {
+	options := kubernetes.Options{
+		Kubeconfig: c.Kubeconfig,
+		ReadOnly:   c.ReadOnly,
+	}
+	identityMode, err := kubernetes.ParseIdentityMode(c.IdentityMode)
+	if err != nil {
+		return options, fmt.Errorf("invalid identity mode configuration: %v", err)
+	}
+	options.IdentityMode = identityMode
+	for _, rule := range c.DeniedResources {
+		deniedResource, err := kubernetes.ParseDeniedResource(rule)
+		if err != nil {
+			return options, fmt.Errorf("invalid denied resources configuration: %v", err)
+		}
+		options.DeniedResources = append(options.DeniedResources, deniedResource)
+	}
+	for _, pattern := range c.AllowedNamespaces {
+		allowedNamespace, err := kubernetes.ParseAllowedNamespace(pattern)
+		if err != nil {
+			return options, fmt.Errorf("invalid allowed namespaces configuration: %v", err)
+		}
+		options.AllowedNamespaces = append(options.AllowedNamespaces, allowedNamespace)
+	}
+	return options, nil
+}
+
+// validateTools checks that the enabled and disabled tools are known tools, regardless of the cluster the server is connected to
+// (e.g. projects_list is only available for OpenShift clusters)
+func (c *Configuration) validateTools() error {
+	var unknownTools []string
+	for _, name := range slices.Concat(c.EnabledTools, c.DisabledTools) {
+		if !slices.Contains(toolNames, name) && !slices.Contains(unknownTools, name) {
+			unknownTools = append(unknownTools, name)
+		}
+	}
+	if len(unknownTools) > 0 {
+		return fmt.Errorf("invalid tools configuration, unknown tools: %s (available tools: %s)",
+			strings.Join(unknownTools, ", "), strings.Join(toolNames, ", "))
+	}
+	return nil
+}
+
+func isReadOnly(tool mcp.Tool) bool {
+	return tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint
+}
+
+// withContext adds the optional context argument to the tool definition
+func withContext() mcp.ToolOption {
+	return mcp.WithString("context",
+		mcp.Description("Optional kubeconfig context to run the operation against. "+
+			"If not provided, the current context will be used (use contexts_list to list the available contexts)"))
+}
+
+// integerArgument returns the value of the integer argument (nil if not provided), or an error if it isn't an integer number
+// (JSON numbers are decoded as float64, a fractional value is rejected instead of being truncated)
+func integerArgument(arguments map[string]any, name string) (*int64, error) {
+	value, ok := arguments[name]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	number, ok := value.(float64)
+	if !ok || number != math.Trunc(number) || math.Abs(number) > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid %s argument %v, must be an integer", name, value)
 	}
+	ret := int64(number)
+	return &ret, nil
 }
 
-func NewTextResult(content string, err error) *mcp.CallToolResult {
+// NewTextResult returns the text result of a tool call, or a failed result if err is not nil.
+// The error is recorded in the tool call of the context so that the instrumentation can report its reason.
+func NewTextResult(ctx context.Context, content string, err error) *mcp.CallToolResult {
 	if err != nil {
-		return &mcp.CallToolResult{
+		result := &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
 				mcp.TextContent{
@@ -77,6 +354,8 @@ func NewTextResult(content string, err error) *mcp.CallToolResult {
 				},
 			},
 		}
+		recordToolError(ctx, err)
+		return result
 	}
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -2,7 +2,11 @@ package mcp
 
 import (
 	"context"
+	"errors"
+	"github.com/manusa/kubernetes-mcp-server/pkg/auth"
+	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
 	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -53,18 +57,35 @@ func TestWatchKubeConfig(t *testing.T) {
 func TestTools(t *testing.T) {
 	expectedNames := []string{
 		"configuration_view",
+		"contexts_list",
 		"events_list",
 		"namespaces_list",
+		"nodes_top",
 		"pods_list",
 		"pods_list_in_namespace",
 		"pods_get",
 		"pods_delete",
+		"pods_exec",
+		"pods_debug",
+		"pods_cp_from",
+		"pods_cp_to",
 		"pods_log",
+		"pods_top",
 		"pods_run",
+		"pods_port_forward_start",
+		"pods_port_forward_list",
+		"pods_port_forward_stop",
+		"http_get",
 		"resources_list",
 		"resources_get",
 		"resources_create_or_update",
 		"resources_delete",
+		"resources_scale",
+		"rollout_status",
+		"rollout_history",
+		"rollout_restart",
+		"rollout_undo",
+		"workload_logs",
 	}
 	testCase(t, func(c *mcpContext) {
 		tools, err := c.mcpClient.ListTools(c.ctx, mcp.ListToolsRequest{})
@@ -86,6 +107,13 @@ func TestTools(t *testing.T) {
 				}
 			})
 		}
+		t.Run("ListTools returns only tools in the tool names list", func(t *testing.T) {
+			for _, tool := range tools.Tools {
+				if !slices.Contains(toolNames, tool.Name) {
+					t.Errorf("tool %s not found in the tool names list", tool.Name)
+				}
+			}
+		})
 	})
 }
 
@@ -107,6 +135,9 @@ func TestToolsInOpenShift(t *testing.T) {
 			if idx == -1 {
 				t.Fatalf("tool projects_list not found")
 			}
+			if !slices.Contains(toolNames, "projects_list") {
+				t.Fatalf("tool projects_list not found in the tool names list")
+			}
 		})
 		t.Run("ListTools has resources_list tool with OpenShift hint", func(t *testing.T) {
 			idx := slices.IndexFunc(tools.Tools, func(tool mcp.Tool) bool {
@@ -122,3 +153,339 @@ func TestToolsInOpenShift(t *testing.T) {
 	})
 
 }
+
+func TestToolsAnnotations(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		tools, err := c.mcpClient.ListTools(c.ctx, mcp.ListToolsRequest{})
+		t.Run("ListTools returns tools", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("call ListTools failed %v", err)
+			}
+		})
+		for _, tool := range tools.Tools {
+			t.Run("ListTools has annotations for "+tool.Name+" tool", func(t *testing.T) {
+				if tool.Annotations.Title == "" {
+					t.Errorf("tool %s has no title annotation", tool.Name)
+				}
+				if tool.Annotations.ReadOnlyHint == nil {
+					t.Errorf("tool %s has no readOnlyHint annotation", tool.Name)
+				}
+				if tool.Annotations.DestructiveHint == nil {
+					t.Errorf("tool %s has no destructiveHint annotation", tool.Name)
+				}
+				if *tool.Annotations.ReadOnlyHint && *tool.Annotations.DestructiveHint {
+					t.Errorf("tool %s is read-only and destructive", tool.Name)
+				}
+			})
+		}
+	})
+}
+
+func TestToolsInReadOnlyMode(t *testing.T) {
+	mutatingNames := []string{
+		"pods_delete",
+		"pods_exec",
+		"pods_debug",
+		"pods_cp_from",
+		"pods_cp_to",
+		"pods_run",
+		"pods_port_forward_start",
+		"pods_port_forward_stop",
+		"http_get",
+		"resources_create_or_update",
+		"resources_delete",
+		"resources_scale",
+		"rollout_restart",
+		"rollout_undo",
+	}
+	testCaseWithContext(t, &mcpContext{readOnly: true}, func(c *mcpContext) {
+		tools, err := c.mcpClient.ListTools(c.ctx, mcp.ListToolsRequest{})
+		t.Run("ListTools returns tools", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("call ListTools failed %v", err)
+			}
+		})
+		t.Run("ListTools returns only read-only tools", func(t *testing.T) {
+			for _, tool := range tools.Tools {
+				if tool.Annotations.ReadOnlyHint == nil || !*tool.Annotations.ReadOnlyHint {
+					t.Errorf("tool %s is not read-only", tool.Name)
+				}
+				if slices.Contains(mutatingNames, tool.Name) {
+					t.Errorf("tool %s should not be exposed in read-only mode", tool.Name)
+				}
+			}
+		})
+		t.Run("ListTools still returns read-only tools", func(t *testing.T) {
+			for _, name := range []string{"pods_list", "pods_port_forward_list"} {
+				if !slices.ContainsFunc(tools.Tools, func(tool mcp.Tool) bool { return tool.Name == name }) {
+					t.Errorf("tool %s not found", name)
+				}
+			}
+		})
+	})
+}
+
+func TestKubernetesInReadOnlyMode(t *testing.T) {
+	testCaseWithContext(t, &mcpContext{readOnly: true}, func(c *mcpContext) {
+		c.withEnvTest()
+		t.Run("read-only client can list resources", func(t *testing.T) {
+			if _, err := c.mcpServer.k.Load().PodsListInAllNamespaces(c.ctx); err != nil {
+				t.Fatalf("list failed %v", err)
+			}
+		})
+		t.Run("read-only client rejects resource creation", func(t *testing.T) {
+			_, err := c.mcpServer.k.Load().ResourcesCreateOrUpdate(c.ctx, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a-read-only-configmap\n  namespace: default\n")
+			if !errors.Is(err, kubernetes.ErrReadOnly) {
+				t.Fatalf("expected read-only error, got %v", err)
+			}
+		})
+		t.Run("read-only client rejects resource deletion", func(t *testing.T) {
+			err := c.mcpServer.k.Load().ResourcesDelete(c.ctx, &schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, "default", "a-configmap-to-delete")
+			if !errors.Is(err, kubernetes.ErrReadOnly) {
+				t.Fatalf("expected read-only error, got %v", err)
+			}
+		})
+		t.Run("read-only client rejects pod deletion", func(t *testing.T) {
+			_, err := c.mcpServer.k.Load().PodsDelete(c.ctx, "default", "a-pod-in-default")
+			if !errors.Is(err, kubernetes.ErrReadOnly) {
+				t.Fatalf("expected read-only error, got %v", err)
+			}
+		})
+	})
+}
+
+func TestKubernetesWithIdentityMode(t *testing.T) {
+	listPods := mcp.CallToolRequest{}
+	listPods.Params.Arguments = map[string]interface{}{}
+	unprivileged := auth.WithUser(context.Background(), &auth.User{Name: "unprivileged", Groups: []string{"unprivileged-group"}, Token: "invalid-token", Passthrough: true})
+	staticTokenUser := auth.WithUser(context.Background(), &auth.User{Name: "static", Token: "static-token"})
+	testCaseWithContext(t, &mcpContext{identityMode: "impersonate"}, func(c *mcpContext) {
+		c.withEnvTest()
+		t.Run("impersonate mode without authenticated user uses server identity", func(t *testing.T) {
+			k, err := c.mcpServer.kubernetesFor(context.Background(), listPods)
+			if err != nil {
+				t.Fatalf("failed to get client %v", err)
+			}
+			if _, err = k.PodsListInNamespace(c.ctx, "default"); err != nil {
+				t.Fatalf("list failed %v", err)
+			}
+		})
+		t.Run("impersonate mode with authenticated user impersonates user", func(t *testing.T) {
+			k, err := c.mcpServer.kubernetesFor(unprivileged, listPods)
+			if err != nil {
+				t.Fatalf("failed to get client %v", err)
+			}
+			_, err = k.PodsListInNamespace(c.ctx, "default")
+			if err == nil || !strings.Contains(err.Error(), `User "unprivileged" cannot list resource "pods"`) {
+				t.Fatalf("expected forbidden error for impersonated user, got %v", err)
+			}
+		})
+	})
+	testCaseWithContext(t, &mcpContext{identityMode: "passthrough"}, func(c *mcpContext) {
+		c.withEnvTest()
+		t.Run("passthrough mode with authenticated user uses user token", func(t *testing.T) {
+			k, err := c.mcpServer.kubernetesFor(unprivileged, listPods)
+			if err != nil {
+				t.Fatalf("failed to get client %v", err)
+			}
+			_, err = k.PodsListInNamespace(c.ctx, "default")
+			if err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+				t.Fatalf("expected unauthorized error for invalid user token, got %v", err)
+			}
+		})
+	})
+	testCaseWithContext(t, &mcpContext{identityMode: "passthrough"}, func(c *mcpContext) {
+		t.Run("passthrough mode with static token user returns error", func(t *testing.T) {
+			_, err := c.mcpServer.kubernetesFor(staticTokenUser, listPods)
+			if err == nil || !strings.HasSuffix(err.Error(), "the token of user static can't be passed through to the Kubernetes API server, only the tokens issued by the OIDC provider can") {
+				t.Fatalf("expected passthrough error for static token user, got %v", err)
+			}
+		})
+	})
+	testCaseWithContext(t, &mcpContext{}, func(c *mcpContext) {
+		c.withEnvTest()
+		t.Run("server mode with authenticated user uses server identity", func(t *testing.T) {
+			k, err := c.mcpServer.kubernetesFor(unprivileged, listPods)
+			if err != nil {
+				t.Fatalf("failed to get client %v", err)
+			}
+			if _, err = k.PodsListInNamespace(c.ctx, "default"); err != nil {
+				t.Fatalf("list failed %v", err)
+			}
+		})
+	})
+	t.Run("invalid identity mode returns error", func(t *testing.T) {
+		_, err := NewSever(Configuration{IdentityMode: "root"})
+		if err == nil || !strings.HasPrefix(err.Error(), `invalid identity mode configuration: invalid identity mode "root"`) {
+			t.Fatalf("expected invalid identity mode error, got %v", err)
+		}
+	})
+}
+
+func TestToolsWithEnabledTools(t *testing.T) {
+	testCaseWithContext(t, &mcpContext{enabledTools: []string{"pods_list", "pods_log"}}, func(c *mcpContext) {
+		tools, err := c.mcpClient.ListTools(c.ctx, mcp.ListToolsRequest{})
+		t.Run("ListTools returns tools", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("call ListTools failed %v", err)
+			}
+		})
+		t.Run("ListTools returns only enabled tools", func(t *testing.T) {
+			if len(tools.Tools) != 2 {
+				t.Fatalf("invalid tools count, expected 2, got %v", len(tools.Tools))
+			}
+			for _, tool := range tools.Tools {
+				if tool.Name != "pods_list" && tool.Name != "pods_log" {
+					t.Errorf("tool %s should not be exposed", tool.Name)
+				}
+			}
+		})
+	})
+}
+
+func TestToolsWithDisabledTools(t *testing.T) {
+	testCaseWithContext(t, &mcpContext{disabledTools: []string{"pods_exec", "resources_delete"}}, func(c *mcpContext) {
+		tools, err := c.mcpClient.ListTools(c.ctx, mcp.ListToolsRequest{})
+		t.Run("ListTools returns tools", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("call ListTools failed %v", err)
+			}
+		})
+		t.Run("ListTools does not return disabled tools", func(t *testing.T) {
+			for _, tool := range tools.Tools {
+				if tool.Name == "pods_exec" || tool.Name == "resources_delete" {
+					t.Errorf("tool %s should not be exposed", tool.Name)
+				}
+			}
+		})
+		t.Run("ListTools returns the rest of tools", func(t *testing.T) {
+			if !slices.ContainsFunc(tools.Tools, func(tool mcp.Tool) bool { return tool.Name == "pods_list" }) {
+				t.Errorf("tool pods_list not found")
+			}
+		})
+	})
+}
+
+func TestToolsWithUnknownTools(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		_, err := NewSever(Configuration{EnabledTools: []string{"pods_list", "pods_teleport"}, DisabledTools: []string{"pods_vanish"}})
+		t.Run("NewServer with unknown tools returns error", func(t *testing.T) {
+			if err == nil {
+				t.Fatalf("expected error for unknown tools")
+			}
+			if !strings.HasPrefix(err.Error(), "invalid tools configuration, unknown tools: pods_teleport, pods_vanish") {
+				t.Fatalf("invalid error message, got %v", err)
+			}
+		})
+	})
+}
+
+func TestToolsWithClusterDependentTools(t *testing.T) {
+	testCaseWithContext(t, &mcpContext{enabledTools: []string{"pods_list", "projects_list"}}, func(c *mcpContext) {
+		tools, err := c.mcpClient.ListTools(c.ctx, mcp.ListToolsRequest{})
+		t.Run("NewServer with tool not available in the cluster succeeds", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("call ListTools failed %v", err)
+			}
+		})
+		t.Run("ListTools returns only the enabled tools available in the cluster", func(t *testing.T) {
+			if len(tools.Tools) != 1 || tools.Tools[0].Name != "pods_list" {
+				t.Fatalf("unexpected tools %v", tools.Tools)
+			}
+		})
+	})
+}
+
+func TestKubeConfigChangeWithInvalidKubeConfig(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		if err := os.WriteFile(filepath.Join(c.tempDir, "config"), []byte("invalid kubeconfig"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		err := c.mcpServer.onKubeConfigChange()
+		t.Run("onKubeConfigChange with invalid kubeconfig returns error", func(t *testing.T) {
+			if err == nil {
+				t.Fatalf("expected error for invalid kubeconfig")
+			}
+		})
+		t.Run("onKubeConfigChange with invalid kubeconfig reports the error in the readiness check", func(t *testing.T) {
+			if err := c.mcpServer.Ready(c.ctx); err == nil || !strings.HasPrefix(err.Error(), "failed to reload kubernetes client, the previous client is still in use: ") {
+				t.Fatalf("unexpected readiness %v", err)
+			}
+		})
+		t.Run("onKubeConfigChange with invalid kubeconfig keeps the previous client", func(t *testing.T) {
+			if c.mcpServer.k.Load() == nil {
+				t.Fatalf("previous client not found")
+			}
+		})
+		tools, _ := c.mcpClient.ListTools(c.ctx, mcp.ListToolsRequest{})
+		t.Run("onKubeConfigChange with invalid kubeconfig keeps the previous tools", func(t *testing.T) {
+			if !slices.ContainsFunc(tools.Tools, func(tool mcp.Tool) bool { return tool.Name == "pods_list" }) {
+				t.Fatalf("tool pods_list not found")
+			}
+		})
+	})
+}
+
+func TestSetConfiguration(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		var notification *mcp.JSONRPCNotification
+		c.mcpClient.OnNotification(func(n mcp.JSONRPCNotification) {
+			notification = &n
+		})
+		err := c.mcpServer.SetConfiguration(Configuration{ReadOnly: true})
+		t.Run("SetConfiguration with valid configuration succeeds", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("SetConfiguration failed %v", err)
+			}
+		})
+		tools, _ := c.mcpClient.ListTools(c.ctx, mcp.ListToolsRequest{})
+		t.Run("SetConfiguration applies read-only configuration", func(t *testing.T) {
+			if slices.ContainsFunc(tools.Tools, func(tool mcp.Tool) bool { return tool.Name == "pods_delete" }) {
+				t.Errorf("tool pods_delete should not be exposed in read-only mode")
+			}
+		})
+		t.Run("SetConfiguration notifies tools change", func(t *testing.T) {
+			if notification == nil || notification.Method != "notifications/tools/list_changed" {
+				t.Errorf("SetConfiguration did not notify tools change, got %v", notification)
+			}
+		})
+		err = c.mcpServer.SetConfiguration(Configuration{EnabledTools: []string{"pods_teleport"}})
+		t.Run("SetConfiguration with invalid configuration returns error", func(t *testing.T) {
+			if err == nil {
+				t.Fatalf("expected error for invalid configuration")
+			}
+		})
+		tools, _ = c.mcpClient.ListTools(c.ctx, mcp.ListToolsRequest{})
+		t.Run("SetConfiguration with invalid configuration keeps previous configuration", func(t *testing.T) {
+			if !c.mcpServer.configuration.Load().ReadOnly {
+				t.Errorf("previous configuration not restored")
+			}
+			if !slices.ContainsFunc(tools.Tools, func(tool mcp.Tool) bool { return tool.Name == "pods_list" }) {
+				t.Errorf("tool pods_list not found")
+			}
+		})
+	})
+}
+
+func TestSetConfigurationWithConcurrentToolCalls(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 10; i++ {
+				_ = c.mcpServer.SetConfiguration(Configuration{ReadOnly: i%2 == 0})
+				_ = c.mcpServer.onKubeConfigChange()
+			}
+		}()
+		for i := 0; i < 10; i++ {
+			_, _ = c.callTool("configuration_view", map[string]interface{}{})
+			_, _ = c.callTool("pods_list", map[string]interface{}{"context": 1})
+		}
+		<-done
+		t.Run("SetConfiguration with concurrent tool calls applies the last configuration", func(t *testing.T) {
+			if c.mcpServer.configuration.Load().ReadOnly {
+				t.Fatalf("unexpected configuration %v", c.mcpServer.configuration.Load())
+			}
+		})
+	})
+}
@@ -12,30 +12,46 @@ func (s *Server) initNamespaces() []server.ServerTool {
 	ret = append(ret, server.ServerTool{
 		Tool: mcp.NewTool("namespaces_list",
 			mcp.WithDescription("List all the Kubernetes namespaces in the current cluster"),
+			withContext(),
+			mcp.WithTitleAnnotation("Namespaces: List"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
 		), Handler: s.namespacesList,
 	})
-	if s.k.IsOpenShift(context.Background()) {
+	if s.k.Load().IsOpenShift(context.Background()) {
 		ret = append(ret, server.ServerTool{
 			Tool: mcp.NewTool("projects_list",
 				mcp.WithDescription("List all the OpenShift projects in the current cluster"),
+				withContext(),
+				mcp.WithTitleAnnotation("Projects: List"),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithDestructiveHintAnnotation(false),
 			), Handler: s.projectsList,
 		})
 	}
 	return ret
 }
 
-func (s *Server) namespacesList(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ret, err := s.k.NamespacesList(ctx)
+func (s *Server) namespacesList(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
 	if err != nil {
-		err = fmt.Errorf("failed to list namespaces: %v", err)
+		return NewTextResult(ctx, "", err), nil
 	}
-	return NewTextResult(ret, err), nil
+	ret, err := k.NamespacesList(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	return NewTextResult(ctx, ret, err), nil
 }
 
-func (s *Server) projectsList(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ret, err := s.k.ProjectsList(ctx)
+func (s *Server) projectsList(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ret, err := k.ProjectsList(ctx)
 	if err != nil {
-		err = fmt.Errorf("failed to list projects: %v", err)
+		err = fmt.Errorf("failed to list projects: %w", err)
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
 }
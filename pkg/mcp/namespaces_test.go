@@ -8,6 +8,7 @@ import (
 	"k8s.io/client-go/dynamic"
 	"sigs.k8s.io/yaml"
 	"slices"
+	"strings"
 	"testing"
 )
 
@@ -88,3 +89,126 @@ func TestProjectsListInOpenShift(t *testing.T) {
 		})
 	})
 }
+
+func TestNamespacesListWithAllowedNamespaces(t *testing.T) {
+	testCaseWithContext(t, &mcpContext{allowedNamespaces: []string{"default", "ns-*"}}, func(c *mcpContext) {
+		c.withEnvTest()
+		toolResult, err := c.callTool("namespaces_list", map[string]interface{}{})
+		t.Run("namespaces_list returns namespace list", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("call tool failed %v", err)
+			}
+			if toolResult.IsError {
+				t.Fatalf("call tool failed")
+			}
+		})
+		var decoded []unstructured.Unstructured
+		err = yaml.Unmarshal([]byte(toolResult.Content[0].(mcp.TextContent).Text), &decoded)
+		t.Run("namespaces_list has yaml content", func(t *testing.T) {
+			if err != nil {
+				t.Fatalf("invalid tool result content %v", err)
+			}
+		})
+		t.Run("namespaces_list returns only allowed namespaces", func(t *testing.T) {
+			for _, ns := range decoded {
+				if ns.GetName() != "default" && !strings.HasPrefix(ns.GetName(), "ns-") {
+					t.Errorf("namespace %s should have been filtered out", ns.GetName())
+				}
+			}
+			for _, expectedNamespace := range []string{"default", "ns-1", "ns-2"} {
+				idx := slices.IndexFunc(decoded, func(ns unstructured.Unstructured) bool {
+					return ns.GetName() == expectedNamespace
+				})
+				if idx == -1 {
+					t.Errorf("namespace %s not found in the list", expectedNamespace)
+				}
+			}
+		})
+	})
+}
+
+func TestAllowedNamespaces(t *testing.T) {
+	testCaseWithContext(t, &mcpContext{allowedNamespaces: []string{"ns-1"}}, func(c *mcpContext) {
+		c.withEnvTest()
+		t.Run("pods_list in all namespaces returns only pods in allowed namespaces", func(t *testing.T) {
+			toolResult, err := c.callTool("pods_list", map[string]interface{}{})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v", err)
+			}
+			var decoded []unstructured.Unstructured
+			if err = yaml.Unmarshal([]byte(toolResult.Content[0].(mcp.TextContent).Text), &decoded); err != nil {
+				t.Fatalf("invalid tool result content %v", err)
+			}
+			if len(decoded) != 1 || decoded[0].GetName() != "a-pod-in-ns-1" {
+				t.Fatalf("invalid pods, expected only a-pod-in-ns-1, got %v", decoded)
+			}
+		})
+		t.Run("events_list in all namespaces returns only events in allowed namespaces", func(t *testing.T) {
+			toolResult, err := c.callTool("events_list", map[string]interface{}{})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v", err)
+			}
+			if strings.Contains(toolResult.Content[0].(mcp.TextContent).Text, "Namespace: default") {
+				t.Fatalf("events in default namespace should have been filtered out")
+			}
+		})
+		t.Run("pods_list_in_namespace with denied namespace returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("pods_list_in_namespace", map[string]interface{}{"namespace": "ns-2"})
+			if !toolResult.IsError {
+				t.Fatalf("call tool should fail")
+			}
+			if toolResult.Content[0].(mcp.TextContent).Text != `failed to list pods in namespace ns-2: namespace not allowed: "ns-2" is not in the allowed namespaces of the server configuration` {
+				t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("pods_get without namespace uses configured (denied) namespace and returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("pods_get", map[string]interface{}{"name": "a-pod-in-default"})
+			if !toolResult.IsError {
+				t.Fatalf("call tool should fail")
+			}
+			if !strings.Contains(toolResult.Content[0].(mcp.TextContent).Text, `namespace not allowed: "default"`) {
+				t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("pods_get in allowed namespace returns pod", func(t *testing.T) {
+			toolResult, err := c.callTool("pods_get", map[string]interface{}{"namespace": "ns-1", "name": "a-pod-in-ns-1"})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v", err)
+			}
+		})
+		t.Run("resources_create_or_update in denied namespace returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_create_or_update", map[string]interface{}{
+				"resource": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a-configmap\n  namespace: ns-2\n",
+			})
+			if !toolResult.IsError {
+				t.Fatalf("call tool should fail")
+			}
+			if !strings.Contains(toolResult.Content[0].(mcp.TextContent).Text, `namespace not allowed: "ns-2"`) {
+				t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("resources_delete of a denied Namespace returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_delete", map[string]interface{}{"apiVersion": "v1", "kind": "Namespace", "name": "ns-2"})
+			if !toolResult.IsError {
+				t.Fatalf("call tool should fail")
+			}
+			if !strings.Contains(toolResult.Content[0].(mcp.TextContent).Text, `namespace not allowed: "ns-2"`) {
+				t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+	})
+}
+
+func TestAllowedNamespacesInvalidConfiguration(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		_, err := NewSever(Configuration{AllowedNamespaces: []string{"ns-["}})
+		t.Run("NewServer with invalid allowed namespaces returns error", func(t *testing.T) {
+			if err == nil {
+				t.Fatalf("expected error for invalid allowed namespaces")
+			}
+			if !strings.HasPrefix(err.Error(), `invalid allowed namespaces configuration: invalid allowed namespace "ns-["`) {
+				t.Fatalf("invalid error message, got %v", err)
+			}
+		})
+	})
+}
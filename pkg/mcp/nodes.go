@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func (s *Server) initNodes() []server.ServerTool {
+	return []server.ServerTool{
+		{Tool: mcp.NewTool("nodes_top",
+			mcp.WithDescription("List the CPU and memory usage of the Kubernetes Nodes, along with their allocatable resources and usage percentage. "+
+				"Requires metrics-server to be installed in the cluster"),
+			mcp.WithString("labelSelector", mcp.Description("Label selector of the Nodes to get the resource usage from, e.g. node-role.kubernetes.io/worker (Optional)")),
+			withTopSortBy(),
+			withContext(),
+			mcp.WithTitleAnnotation("Nodes: Top"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.nodesTop},
+	}
+}
+
+func (s *Server) nodesTop(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ret, err := k.NodesTop(ctx, topOptions(ctr.GetArguments()))
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get nodes resource usage: %w", err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
+
+// withTopSortBy adds the optional sortBy argument to the top tool definitions
+func withTopSortBy() mcp.ToolOption {
+	return mcp.WithString("sortBy", mcp.Description("Sort the results by descending CPU or memory usage (Optional, sorted by name if not provided)"),
+		mcp.Enum(kubernetes.TopSortByCPU, kubernetes.TopSortByMemory))
+}
+
+func topOptions(arguments map[string]interface{}) kubernetes.TopOptions {
+	options := kubernetes.TopOptions{}
+	options.LabelSelector, _ = arguments["labelSelector"].(string)
+	options.SortBy, _ = arguments["sortBy"].(string)
+	return options
+}
@@ -2,183 +2,590 @@ package mcp
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	podsLogFollowDefaultDuration = 30 * time.Second
+	podsLogFollowMaxDuration     = 5 * time.Minute
+	podsLogFollowDefaultLines    = 1000
 )
 
 func (s *Server) initPods() []server.ServerTool {
 	return []server.ServerTool{
-		{mcp.NewTool("pods_list",
+		{Tool: mcp.NewTool("pods_list",
 			mcp.WithDescription("List all the Kubernetes pods in the current cluster from all namespaces"),
-		), s.podsListInAllNamespaces},
-		{mcp.NewTool("pods_list_in_namespace",
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: List"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.podsListInAllNamespaces},
+		{Tool: mcp.NewTool("pods_list_in_namespace",
 			mcp.WithDescription("List all the Kubernetes pods in the specified namespace in the current cluster"),
 			mcp.WithString("namespace", mcp.Description("Namespace to list pods from"), mcp.Required()),
-		), s.podsListInNamespace},
-		{mcp.NewTool("pods_get",
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: List in Namespace"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.podsListInNamespace},
+		{Tool: mcp.NewTool("pods_get",
 			mcp.WithDescription("Get a Kubernetes Pod in the current or provided namespace with the provided name"),
 			mcp.WithString("namespace", mcp.Description("Namespace to get the Pod from")),
 			mcp.WithString("name", mcp.Description("Name of the Pod"), mcp.Required()),
-		), s.podsGet},
-		{mcp.NewTool("pods_delete",
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: Get"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.podsGet},
+		{Tool: mcp.NewTool("pods_delete",
 			mcp.WithDescription("Delete a Kubernetes Pod in the current or provided namespace with the provided name"),
 			mcp.WithString("namespace", mcp.Description("Namespace to delete the Pod from")),
 			mcp.WithString("name", mcp.Description("Name of the Pod to delete"), mcp.Required()),
-		), s.podsDelete},
-		{mcp.NewTool("pods_exec",
-			mcp.WithDescription("Execute a command in a Kubernetes Pod in the current or provided namespace with the provided name and command"),
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: Delete"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		), Handler: s.podsDelete},
+		{Tool: mcp.NewTool("pods_exec",
+			mcp.WithDescription("Execute a command in a Kubernetes Pod in the current or provided namespace with the provided name and command, returns the stdout, stderr, and exit code of the command "+
+				fmt.Sprintf("(stdout and stderr are truncated to their first %d bytes)", kubernetes.PodsExecMaxOutputBytes)),
 			mcp.WithString("namespace", mcp.Description("Namespace to get the Pod logs from")),
 			mcp.WithString("name", mcp.Description("Name of the Pod to get the logs from"), mcp.Required()),
-			mcp.WithArray("command", mcp.Description("Command to execute in the Pod container. "+
-				"The first item is the command to be run, and the rest are the arguments to that command. "+
-				`Example: ["ls", "-l", "/tmp"]`),
-				// TODO: manual fix to ensure that the items property gets initialized (Gemini)
-				// https://www.googlecloudcommunity.com/gc/AI-ML/Gemini-API-400-Bad-Request-Array-fields-breaks-function-calling/m-p/769835?nobounce
-				func(schema map[string]interface{}) {
-					schema["type"] = "array"
-					schema["items"] = map[string]interface{}{
-						"type": "string",
-					}
-				},
-				mcp.Required(),
-			),
-		), s.podsExec},
-		{mcp.NewTool("pods_log",
+			withCommand("Command to execute in the Pod container"),
+			mcp.WithString("container", mcp.Description("Name of the Pod container to execute the command in, including init and ephemeral containers (Optional, defaults to the kubectl.kubernetes.io/default-container annotation or the first container)")),
+			mcp.WithString("stdin", mcp.Description("Input passed to the standard input of the command (Optional)")),
+			mcp.WithNumber("timeout", mcp.Description(fmt.Sprintf("Maximum number of seconds the command can run before it's terminated (Optional, defaults to %d, maximum %d)",
+				int(kubernetes.PodsExecDefaultTimeout.Seconds()), int(kubernetes.PodsExecMaxTimeout.Seconds())))),
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: Exec"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+		), Handler: s.podsExec},
+		{Tool: mcp.NewTool("pods_debug",
+			mcp.WithDescription("Debug a Kubernetes Pod in the current or provided namespace by adding an ephemeral container with debugging tools and executing a command in it, "+
+				"useful when the Pod containers don't provide a shell (e.g. distroless images). Returns the stdout, stderr, and exit code of the command. "+
+				"Ephemeral containers can't be removed, the debug container remains in the Pod until it's deleted"),
+			mcp.WithString("namespace", mcp.Description("Namespace of the Pod to debug")),
+			mcp.WithString("name", mcp.Description("Name of the Pod to debug"), mcp.Required()),
+			withCommand("Command to execute in the ephemeral debug container"),
+			mcp.WithString("image", mcp.Description(fmt.Sprintf("Container image of the ephemeral debug container (Optional, defaults to %s)", kubernetes.PodsDebugDefaultImage))),
+			mcp.WithString("target", mcp.Description("Name of the Pod container whose process namespace is shared with the ephemeral debug container, "+
+				"allows inspecting its processes and filesystem through /proc/1/root (Optional)")),
+			mcp.WithNumber("timeout", mcp.Description(fmt.Sprintf("Maximum number of seconds the command can run before it's terminated (Optional, defaults to %d, maximum %d)",
+				int(kubernetes.PodsExecDefaultTimeout.Seconds()), int(kubernetes.PodsExecMaxTimeout.Seconds())))),
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: Debug"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+		), Handler: s.podsDebug},
+		{Tool: mcp.NewTool("pods_cp_from",
+			mcp.WithDescription(fmt.Sprintf("Copy a file from a Kubernetes Pod container in the current or provided namespace (as kubectl cp, the container image must provide tar). "+
+				"Returns the file content as text, or base64 encoded if the file is binary (maximum %d bytes)", kubernetes.PodsCpMaxBytes)),
+			mcp.WithString("namespace", mcp.Description("Namespace of the Pod to copy the file from")),
+			mcp.WithString("name", mcp.Description("Name of the Pod to copy the file from"), mcp.Required()),
+			mcp.WithString("path", mcp.Description("Path of the file in the Pod container, e.g. /tmp/heap.hprof"), mcp.Required()),
+			mcp.WithString("container", mcp.Description("Name of the Pod container to copy the file from (Optional, defaults to the kubectl.kubernetes.io/default-container annotation or the first container)")),
+			mcp.WithNumber("timeout", mcp.Description(fmt.Sprintf("Maximum number of seconds the copy can take (Optional, defaults to %d, maximum %d)",
+				int(kubernetes.PodsExecDefaultTimeout.Seconds()), int(kubernetes.PodsExecMaxTimeout.Seconds())))),
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: Copy From"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.podsCpFrom},
+		{Tool: mcp.NewTool("pods_cp_to",
+			mcp.WithDescription(fmt.Sprintf("Copy the provided content to a file in a Kubernetes Pod container in the current or provided namespace (as kubectl cp, the container image must provide tar). "+
+				"The file is created or overwritten (maximum %d bytes)", kubernetes.PodsCpMaxBytes)),
+			mcp.WithString("namespace", mcp.Description("Namespace of the Pod to copy the file to")),
+			mcp.WithString("name", mcp.Description("Name of the Pod to copy the file to"), mcp.Required()),
+			mcp.WithString("path", mcp.Description("Path of the file in the Pod container, its directory must exist, e.g. /etc/app/config.yaml"), mcp.Required()),
+			mcp.WithString("content", mcp.Description("Content of the file"), mcp.Required()),
+			mcp.WithString("encoding", mcp.Description("Encoding of the provided content, base64 for binary files (Optional, defaults to text)"),
+				mcp.Enum(kubernetes.EncodingText, kubernetes.EncodingBase64)),
+			mcp.WithString("container", mcp.Description("Name of the Pod container to copy the file to (Optional, defaults to the kubectl.kubernetes.io/default-container annotation or the first container)")),
+			mcp.WithNumber("timeout", mcp.Description(fmt.Sprintf("Maximum number of seconds the copy can take (Optional, defaults to %d, maximum %d)",
+				int(kubernetes.PodsExecDefaultTimeout.Seconds()), int(kubernetes.PodsExecMaxTimeout.Seconds())))),
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: Copy To"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		), Handler: s.podsCpTo},
+		{Tool: mcp.NewTool("pods_log",
 			mcp.WithDescription("Get the logs of a Kubernetes Pod in the current or provided namespace with the provided name"),
 			mcp.WithString("namespace", mcp.Description("Namespace to get the Pod logs from")),
 			mcp.WithString("name", mcp.Description("Name of the Pod to get the logs from"), mcp.Required()),
-		), s.podsLog},
-		{mcp.NewTool("pods_run",
+			mcp.WithString("container", mcp.Description("Name of the Pod container to get the logs from (Optional, required if the Pod has more than one container)")),
+			mcp.WithBoolean("previous", mcp.Description("Return the logs of the previous terminated container instance, useful to triage crashing containers (Optional, defaults to false)")),
+			mcp.WithNumber("tail", mcp.Description(fmt.Sprintf("Number of lines from the end of the logs to return (Optional, defaults to %d unless sinceSeconds or sinceTime are provided)", kubernetes.PodsLogDefaultTailLines))),
+			mcp.WithNumber("sinceSeconds", mcp.Description("Return only the logs newer than the provided number of seconds (Optional)")),
+			mcp.WithString("sinceTime", mcp.Description("Return only the logs newer than the provided RFC3339 timestamp, e.g. 2025-01-01T10:00:00Z (Optional)")),
+			mcp.WithBoolean("timestamps", mcp.Description("Prefix each log line with its RFC3339 timestamp (Optional, defaults to false)")),
+			mcp.WithString("filter", mcp.Description("Regular expression to return only the matching log lines, e.g. (?i)error|warn (Optional)")),
+			mcp.WithBoolean("follow", mcp.Description("Follow the logs and stream the new lines as progress notifications (requires a progress token, otherwise the lines are returned in the result) until followSeconds or followLines is reached or the request is cancelled. "+
+				"Only the new lines are streamed unless tail, sinceSeconds, or sinceTime are provided (Optional, defaults to false)")),
+			mcp.WithNumber("followSeconds", mcp.Description(fmt.Sprintf("Maximum number of seconds to follow the logs (Optional, defaults to %d, maximum %d)",
+				int(podsLogFollowDefaultDuration.Seconds()), int(podsLogFollowMaxDuration.Seconds())))),
+			mcp.WithNumber("followLines", mcp.Description(fmt.Sprintf("Maximum number of lines to stream when following the logs (Optional, defaults to %d)", podsLogFollowDefaultLines))),
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: Log"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.podsLog},
+		{Tool: mcp.NewTool("pods_top",
+			mcp.WithDescription("List the CPU and memory usage of the Kubernetes Pods and their containers in all namespaces or in the provided namespace, "+
+				"along with their resource requests and limits. Requires metrics-server to be installed in the cluster"),
+			mcp.WithString("namespace", mcp.Description("Namespace to get the Pod resource usage from (Optional, all namespaces if not provided)")),
+			mcp.WithString("labelSelector", mcp.Description("Label selector of the Pods to get the resource usage from, e.g. app=nginx (Optional)")),
+			withTopSortBy(),
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: Top"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.podsTop},
+		{Tool: mcp.NewTool("pods_run",
 			mcp.WithDescription("Run a Kubernetes Pod in the current or provided namespace with the provided container image and optional name"),
 			mcp.WithString("namespace", mcp.Description("Namespace to run the Pod in")),
 			mcp.WithString("name", mcp.Description("Name of the Pod (Optional, random name if not provided)")),
 			mcp.WithString("image", mcp.Description("Container Image to run in the Pod"), mcp.Required()),
 			mcp.WithNumber("port", mcp.Description("TCP/IP port to expose from the Pod container (Optional, no port exposed if not provided)")),
-		), s.podsRun},
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: Run"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.podsRun},
 	}
 }
 
-func (s *Server) podsListInAllNamespaces(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ret, err := s.k.PodsListInAllNamespaces(ctx)
+func (s *Server) podsListInAllNamespaces(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ret, err := k.PodsListInAllNamespaces(ctx)
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to list pods in all namespaces: %v", err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to list pods in all namespaces: %w", err)), nil
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
 }
 
 func (s *Server) podsListInNamespace(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ns := ctr.Params.Arguments["namespace"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns := ctr.GetArguments()["namespace"]
 	if ns == nil {
-		return NewTextResult("", errors.New("failed to list pods in namespace, missing argument namespace")), nil
+		return NewTextResult(ctx, "", errors.New("failed to list pods in namespace, missing argument namespace")), nil
 	}
-	ret, err := s.k.PodsListInNamespace(ctx, ns.(string))
+	ret, err := k.PodsListInNamespace(ctx, ns.(string))
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to list pods in namespace %s: %v", ns, err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to list pods in namespace %s: %w", ns, err)), nil
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
 }
 
 func (s *Server) podsGet(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ns := ctr.Params.Arguments["namespace"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns := ctr.GetArguments()["namespace"]
 	if ns == nil {
 		ns = ""
 	}
-	name := ctr.Params.Arguments["name"]
+	name := ctr.GetArguments()["name"]
 	if name == nil {
-		return NewTextResult("", errors.New("failed to get pod, missing argument name")), nil
+		return NewTextResult(ctx, "", errors.New("failed to get pod, missing argument name")), nil
 	}
-	ret, err := s.k.PodsGet(ctx, ns.(string), name.(string))
+	ret, err := k.PodsGet(ctx, ns.(string), name.(string))
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to get pod %s in namespace %s: %v", name, ns, err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get pod %s in namespace %s: %w", name, ns, err)), nil
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
 }
 
 func (s *Server) podsDelete(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ns := ctr.Params.Arguments["namespace"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns := ctr.GetArguments()["namespace"]
 	if ns == nil {
 		ns = ""
 	}
-	name := ctr.Params.Arguments["name"]
+	name := ctr.GetArguments()["name"]
 	if name == nil {
-		return NewTextResult("", errors.New("failed to delete pod, missing argument name")), nil
+		return NewTextResult(ctx, "", errors.New("failed to delete pod, missing argument name")), nil
 	}
-	ret, err := s.k.PodsDelete(ctx, ns.(string), name.(string))
+	ret, err := k.PodsDelete(ctx, ns.(string), name.(string))
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to delete pod %s in namespace %s: %v", name, ns, err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to delete pod %s in namespace %s: %w", name, ns, err)), nil
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
 }
 
 func (s *Server) podsExec(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ns := ctr.Params.Arguments["namespace"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns := ctr.GetArguments()["namespace"]
 	if ns == nil {
 		ns = ""
 	}
-	name := ctr.Params.Arguments["name"]
+	name := ctr.GetArguments()["name"]
 	if name == nil {
-		return NewTextResult("", errors.New("failed to exec in pod, missing argument name")), nil
+		return NewTextResult(ctx, "", errors.New("failed to exec in pod, missing argument name")), nil
 	}
-	commandArg := ctr.Params.Arguments["command"]
-	command := make([]string, 0)
-	if _, ok := commandArg.([]interface{}); ok {
-		for _, cmd := range commandArg.([]interface{}) {
-			if _, ok := cmd.(string); ok {
-				command = append(command, cmd.(string))
-			}
+	command, ok := commandArgument(ctr.GetArguments())
+	if !ok {
+		return NewTextResult(ctx, "", errors.New("failed to exec in pod, invalid command argument")), nil
+	}
+	options := kubernetes.PodsExecOptions{}
+	if container, ok := ctr.GetArguments()["container"].(string); ok {
+		options.Container = container
+	}
+	if stdin, ok := ctr.GetArguments()["stdin"].(string); ok {
+		options.Stdin = stdin
+	}
+	if timeout, ok := ctr.GetArguments()["timeout"].(float64); ok {
+		options.Timeout = time.Duration(timeout * float64(time.Second))
+	}
+	ret, err := k.PodsExec(ctx, ns.(string), name.(string), command, options)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to exec in pod %s in namespace %s: %w", name, ns, err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
+
+func (s *Server) podsDebug(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns := ctr.GetArguments()["namespace"]
+	if ns == nil {
+		ns = ""
+	}
+	name := ctr.GetArguments()["name"]
+	if name == nil {
+		return NewTextResult(ctx, "", errors.New("failed to debug pod, missing argument name")), nil
+	}
+	command, ok := commandArgument(ctr.GetArguments())
+	if !ok {
+		return NewTextResult(ctx, "", errors.New("failed to debug pod, invalid command argument")), nil
+	}
+	options := kubernetes.PodsDebugOptions{}
+	if image, ok := ctr.GetArguments()["image"].(string); ok {
+		options.Image = image
+	}
+	if target, ok := ctr.GetArguments()["target"].(string); ok {
+		options.Target = target
+	}
+	if timeout, ok := ctr.GetArguments()["timeout"].(float64); ok {
+		options.Timeout = time.Duration(timeout * float64(time.Second))
+	}
+	ret, err := k.PodsDebug(ctx, ns.(string), name.(string), command, options)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to debug pod %s in namespace %s: %w", name, ns, err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
+
+func (s *Server) podsCpFrom(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns := ctr.GetArguments()["namespace"]
+	if ns == nil {
+		ns = ""
+	}
+	name := ctr.GetArguments()["name"]
+	if name == nil {
+		return NewTextResult(ctx, "", errors.New("failed to copy from pod, missing argument name")), nil
+	}
+	path, ok := ctr.GetArguments()["path"].(string)
+	if !ok {
+		return NewTextResult(ctx, "", errors.New("failed to copy from pod, missing argument path")), nil
+	}
+	ret, err := k.PodsCpFrom(ctx, ns.(string), name.(string), path, podsCpOptions(ctr.GetArguments()))
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to copy %s from pod %s in namespace %s: %w", path, name, ns, err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
+
+func (s *Server) podsCpTo(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns := ctr.GetArguments()["namespace"]
+	if ns == nil {
+		ns = ""
+	}
+	name := ctr.GetArguments()["name"]
+	if name == nil {
+		return NewTextResult(ctx, "", errors.New("failed to copy to pod, missing argument name")), nil
+	}
+	path, ok := ctr.GetArguments()["path"].(string)
+	if !ok {
+		return NewTextResult(ctx, "", errors.New("failed to copy to pod, missing argument path")), nil
+	}
+	contentArg, ok := ctr.GetArguments()["content"].(string)
+	if !ok {
+		return NewTextResult(ctx, "", errors.New("failed to copy to pod, missing argument content")), nil
+	}
+	content := []byte(contentArg)
+	switch encoding, _ := ctr.GetArguments()["encoding"].(string); encoding {
+	case "", kubernetes.EncodingText:
+	case kubernetes.EncodingBase64:
+		if content, err = base64.StdEncoding.DecodeString(contentArg); err != nil {
+			return NewTextResult(ctx, "", fmt.Errorf("failed to copy %s to pod %s in namespace %s, invalid base64 content: %w", path, name, ns, err)), nil
 		}
-	} else {
-		return NewTextResult("", errors.New("failed to exec in pod, invalid command argument")), nil
+	default:
+		return NewTextResult(ctx, "", fmt.Errorf("failed to copy %s to pod %s in namespace %s, invalid encoding %s", path, name, ns, encoding)), nil
 	}
-	ret, err := s.k.PodsExec(ctx, ns.(string), name.(string), "", command)
+	ret, err := k.PodsCpTo(ctx, ns.(string), name.(string), path, content, podsCpOptions(ctr.GetArguments()))
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to exec in pod %s in namespace %s: %v", name, ns, err)), nil
-	} else if ret == "" {
-		ret = fmt.Sprintf("The executed command in pod %s in namespace %s has not produced any output", name, ns)
+		return NewTextResult(ctx, "", fmt.Errorf("failed to copy %s to pod %s in namespace %s: %w", path, name, ns, err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
+
+func podsCpOptions(arguments map[string]any) kubernetes.PodsCpOptions {
+	options := kubernetes.PodsCpOptions{}
+	if container, ok := arguments["container"].(string); ok {
+		options.Container = container
+	}
+	if timeout, ok := arguments["timeout"].(float64); ok {
+		options.Timeout = time.Duration(timeout * float64(time.Second))
 	}
-	return NewTextResult(ret, err), nil
+	return options
 }
 
 func (s *Server) podsLog(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ns := ctr.Params.Arguments["namespace"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns := ctr.GetArguments()["namespace"]
 	if ns == nil {
 		ns = ""
 	}
-	name := ctr.Params.Arguments["name"]
+	name := ctr.GetArguments()["name"]
 	if name == nil {
-		return NewTextResult("", errors.New("failed to get pod log, missing argument name")), nil
+		return NewTextResult(ctx, "", errors.New("failed to get pod log, missing argument name")), nil
+	}
+	options, err := podsLogOptions(ctr.GetArguments())
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get pod %s log in namespace %s: %w", name, ns, err)), nil
+	}
+	if follow, _ := ctr.GetArguments()["follow"].(bool); follow {
+		return s.podsLogFollow(ctx, ctr, k, ns.(string), name.(string), options), nil
 	}
-	ret, err := s.k.PodsLog(ctx, ns.(string), name.(string))
+	ret, err := k.PodsLog(ctx, ns.(string), name.(string), options)
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to get pod %s log in namespace %s: %v", name, ns, err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get pod %s log in namespace %s: %w", name, ns, err)), nil
+	} else if ret == "" && options.Filter != nil {
+		ret = fmt.Sprintf("The pod %s in namespace %s has not logged any message matching %s", name, ns, options.Filter)
 	} else if ret == "" {
 		ret = fmt.Sprintf("The pod %s in namespace %s has not logged any message yet", name, ns)
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
+}
+
+// podsLogFollow follows the logs of the Pod for a bounded duration and number of lines.
+// If the client provided a progress token, each line is sent as a progress notification and a summary is returned,
+// otherwise the lines are returned in the result.
+func (s *Server) podsLogFollow(ctx context.Context, ctr mcp.CallToolRequest, k *kubernetes.Kubernetes, ns, name string, options kubernetes.PodsLogOptions) *mcp.CallToolResult {
+	duration := podsLogFollowDefaultDuration
+	if followSeconds, ok := ctr.GetArguments()["followSeconds"].(float64); ok && followSeconds > 0 {
+		duration = time.Duration(followSeconds * float64(time.Second))
+	}
+	if duration > podsLogFollowMaxDuration {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to follow pod %s log in namespace %s: followSeconds must not exceed %d",
+			name, ns, int(podsLogFollowMaxDuration.Seconds())))
+	}
+	maxLines := podsLogFollowDefaultLines
+	if followLines, err := integerArgument(ctr.GetArguments(), "followLines"); err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to follow pod %s log in namespace %s: %w", name, ns, err))
+	} else if followLines != nil && *followLines > 0 {
+		maxLines = int(*followLines)
+	}
+	followCtx, cancel := s.cancellations.cancellable(ctx, ctr)
+	defer cancel()
+	followCtx, cancelTimeout := context.WithTimeout(followCtx, duration)
+	defer cancelTimeout()
+	token := progressToken(ctr)
+	var lines []string
+	count, size, truncated := 0, 0, false
+	start := time.Now()
+	err := k.PodsLogFollow(followCtx, ns, name, options, func(line string) bool {
+		count++
+		if token == nil {
+			// Only the most recent lines within the maximum log size are returned
+			lines = append(lines, line)
+			size += len(line)
+			for size > kubernetes.PodsLogMaxBytes && len(lines) > 0 {
+				size -= len(lines[0])
+				lines = lines[1:]
+				truncated = true
+			}
+		} else if err := s.server.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      count,
+			"message":       strings.TrimSuffix(line, "\n"),
+		}); err != nil {
+			klog.V(2).InfoS("Failed to send log line progress notification", "sessionId", sessionID(ctx), "err", err)
+		}
+		return count < maxLines
+	})
+	var stopReason string
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		stopReason = fmt.Sprintf("the time limit of %s was reached", duration)
+	case errors.Is(err, context.Canceled):
+		stopReason = "the request was cancelled"
+	case err != nil:
+		return NewTextResult(ctx, "", fmt.Errorf("failed to follow pod %s log in namespace %s: %w", name, ns, err))
+	case count >= maxLines:
+		stopReason = fmt.Sprintf("the line limit of %d was reached", maxLines)
+	default:
+		stopReason = "the log stream ended"
+	}
+	summary := fmt.Sprintf("Followed the logs of pod %s in namespace %s for %s, %d lines streamed, stopped because %s",
+		name, ns, time.Since(start).Round(time.Millisecond), count, stopReason)
+	if token != nil {
+		return NewTextResult(ctx, summary+" (the lines were sent as progress notifications)", nil)
+	}
+	if truncated {
+		summary += fmt.Sprintf(", the log exceeds %d bytes and only the most recent lines are returned", kubernetes.PodsLogMaxBytes)
+	}
+	return NewTextResult(ctx, "# "+summary+"\n"+strings.Join(lines, ""), nil)
+}
+
+func podsLogOptions(arguments map[string]any) (kubernetes.PodsLogOptions, error) {
+	options := kubernetes.PodsLogOptions{}
+	if container, ok := arguments["container"].(string); ok {
+		options.Container = container
+	}
+	if previous, ok := arguments["previous"].(bool); ok {
+		options.Previous = previous
+	}
+	if tail, err := integerArgument(arguments, "tail"); err != nil {
+		return options, err
+	} else if tail != nil {
+		options.TailLines = *tail
+	}
+	if sinceSeconds, err := integerArgument(arguments, "sinceSeconds"); err != nil {
+		return options, err
+	} else if sinceSeconds != nil {
+		options.SinceSeconds = *sinceSeconds
+	}
+	if sinceTime, ok := arguments["sinceTime"].(string); ok && sinceTime != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceTime)
+		if err != nil {
+			return options, fmt.Errorf("invalid sinceTime %s, expected RFC3339 format", sinceTime)
+		}
+		options.SinceTime = &metav1.Time{Time: parsed}
+	}
+	if timestamps, ok := arguments["timestamps"].(bool); ok {
+		options.Timestamps = timestamps
+	}
+	if filter, ok := arguments["filter"].(string); ok && filter != "" {
+		var err error
+		if options.Filter, err = regexp.Compile(filter); err != nil {
+			return options, fmt.Errorf("invalid filter regular expression: %w", err)
+		}
+	}
+	return options, nil
+}
+
+func (s *Server) podsTop(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns, _ := ctr.GetArguments()["namespace"].(string)
+	ret, err := k.PodsTop(ctx, ns, topOptions(ctr.GetArguments()))
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get pods resource usage: %w", err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
 }
 
 func (s *Server) podsRun(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ns := ctr.Params.Arguments["namespace"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns := ctr.GetArguments()["namespace"]
 	if ns == nil {
 		ns = ""
 	}
-	name := ctr.Params.Arguments["name"]
+	name := ctr.GetArguments()["name"]
 	if name == nil {
 		name = ""
 	}
-	image := ctr.Params.Arguments["image"]
+	image := ctr.GetArguments()["image"]
 	if image == nil {
-		return NewTextResult("", errors.New("failed to run pod, missing argument image")), nil
+		return NewTextResult(ctx, "", errors.New("failed to run pod, missing argument image")), nil
+	}
+	port, err := integerArgument(ctr.GetArguments(), "port")
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to run pod, %s", err)), nil
 	}
-	port := ctr.Params.Arguments["port"]
 	if port == nil {
-		port = float64(0)
+		port = new(int64)
 	}
-	ret, err := s.k.PodsRun(ctx, ns.(string), name.(string), image.(string), int32(port.(float64)))
+	ret, err := k.PodsRun(ctx, ns.(string), name.(string), image.(string), int32(*port))
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to get pod %s log in namespace %s: %v", name, ns, err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get pod %s log in namespace %s: %w", name, ns, err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
+
+// withCommand adds the required command argument to the tool definition
+func withCommand(description string) mcp.ToolOption {
+	return mcp.WithArray("command", mcp.Description(description+". "+
+		"The first item is the command to be run, and the rest are the arguments to that command. "+
+		`Example: ["ls", "-l", "/tmp"]`),
+		// TODO: manual fix to ensure that the items property gets initialized (Gemini)
+		// https://www.googlecloudcommunity.com/gc/AI-ML/Gemini-API-400-Bad-Request-Array-fields-breaks-function-calling/m-p/769835?nobounce
+		func(schema map[string]interface{}) {
+			schema["type"] = "array"
+			schema["items"] = map[string]interface{}{
+				"type": "string",
+			}
+		},
+		mcp.Required(),
+	)
+}
+
+// commandArgument returns the items of the command argument, false if the argument is not an array
+func commandArgument(arguments map[string]any) ([]string, bool) {
+	commandArg, ok := arguments["command"].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	command := make([]string, 0)
+	for _, cmd := range commandArg {
+		if _, ok := cmd.(string); ok {
+			command = append(command, cmd.(string))
+		}
 	}
-	return NewTextResult(ret, err), nil
+	return command, true
 }
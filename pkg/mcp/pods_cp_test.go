@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"github.com/mark3labs/mcp-go/mcp"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/remotecommand"
+	"net/http"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"testing"
+)
+
+func TestPodsCp(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		var copiedCommand, copiedName string
+		var copiedContent []byte
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/api/v1/namespaces/default/pods/pod-to-cp/exec" {
+				return
+			}
+			var stdin, stdout, stderr bytes.Buffer
+			streamOptions := &StreamOptions{Stdout: &stdout, Stderr: &stderr}
+			if req.URL.Query().Get("stdin") == "true" {
+				streamOptions.Stdin = &stdin
+			}
+			ctx, err := createHTTPStreams(w, req, streamOptions)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(err.Error()))
+				return
+			}
+			defer ctx.conn.Close()
+			command := req.URL.Query()["command"]
+			if strings.Join(command[:2], " ") == "tar xmf" {
+				copiedCommand = strings.Join(command, " ")
+				tarReader := tar.NewReader(ctx.stdinStream)
+				header, _ := tarReader.Next()
+				copiedName = header.Name
+				copiedContent, _ = io.ReadAll(tarReader)
+				_, _ = io.Copy(io.Discard, ctx.stdinStream)
+				return
+			}
+			tarWriter := tar.NewWriter(ctx.stdoutStream)
+			switch file := command[len(command)-1]; file {
+			case "config.txt":
+				_ = tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: file, Mode: 0644, Size: 12})
+				_, _ = tarWriter.Write([]byte("key: value\n\n"))
+			case "heap.bin":
+				_ = tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: file, Mode: 0644, Size: 4})
+				_, _ = tarWriter.Write([]byte{0xCA, 0xFE, 0x00, 0xFF})
+			case "huge.bin":
+				_ = tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: file, Mode: 0644, Size: 2 * 1024 * 1024})
+				_, _ = tarWriter.Write(make([]byte, 2*1024*1024))
+			case "logs":
+				_ = tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: file + "/", Mode: 0755})
+			default:
+				_, _ = io.WriteString(ctx.stderrStream, "tar: "+file+": No such file or directory\n")
+				_ = ctx.writeStatus(&apierrors.StatusError{ErrStatus: metav1.Status{
+					Status:  metav1.StatusFailure,
+					Reason:  remotecommand.NonZeroExitCodeReason,
+					Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{{Type: remotecommand.ExitCodeCauseType, Message: "2"}}},
+				}})
+				return
+			}
+			_ = tarWriter.Close()
+		}))
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/api/v1/namespaces/default/pods/pod-to-cp" {
+				return
+			}
+			writeObject(w, &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-to-cp"},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app"}}},
+			})
+		}))
+		cpResult := func(toolResult *mcp.CallToolResult) map[string]interface{} {
+			var result map[string]interface{}
+			_ = yaml.Unmarshal([]byte(toolResult.Content[0].(mcp.TextContent).Text), &result)
+			return result
+		}
+		toolResult, err := c.callTool("pods_cp_from", map[string]interface{}{"namespace": "default", "name": "pod-to-cp", "path": "/etc/app/config.txt"})
+		t.Run("pods_cp_from with text file returns text content", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			result := cpResult(toolResult)
+			if result["encoding"] != "text" || result["content"] != "key: value\n\n" || result["size"] != float64(12) || result["container"] != "app" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, err = c.callTool("pods_cp_from", map[string]interface{}{"namespace": "default", "name": "pod-to-cp", "path": "/tmp/heap.bin"})
+		t.Run("pods_cp_from with binary file returns base64 content", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			result := cpResult(toolResult)
+			if result["encoding"] != "base64" || result["content"] != base64.StdEncoding.EncodeToString([]byte{0xCA, 0xFE, 0x00, 0xFF}) {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_cp_from", map[string]interface{}{"namespace": "default", "name": "pod-to-cp", "path": "/tmp/huge.bin"})
+		t.Run("pods_cp_from with file exceeding the size limit returns error", func(t *testing.T) {
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != "failed to copy /tmp/huge.bin from pod pod-to-cp in namespace default: "+
+				"/tmp/huge.bin size 2097152 bytes exceeds the maximum of 1048576 bytes" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_cp_from", map[string]interface{}{"namespace": "default", "name": "pod-to-cp", "path": "/var/logs"})
+		t.Run("pods_cp_from with directory returns error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.HasSuffix(toolResult.Content[0].(mcp.TextContent).Text, "/var/logs is a directory, only files can be copied") {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_cp_from", map[string]interface{}{"namespace": "default", "name": "pod-to-cp", "path": "/tmp/missing"})
+		t.Run("pods_cp_from with missing file returns tar error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.HasSuffix(toolResult.Content[0].(mcp.TextContent).Text, "failed to read /tmp/missing: tar: missing: No such file or directory") {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, err = c.callTool("pods_cp_to", map[string]interface{}{
+			"namespace": "default", "name": "pod-to-cp", "path": "/etc/app/config.yaml", "content": "key: value\n",
+		})
+		t.Run("pods_cp_to with text content copies the file", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			if toolResult.Content[0].(mcp.TextContent).Text != "File /etc/app/config.yaml (11 bytes) copied to container app of pod pod-to-cp in namespace default" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+			if copiedCommand != "tar xmf - -C /etc/app" || copiedName != "config.yaml" || string(copiedContent) != "key: value\n" {
+				t.Fatalf("unexpected copy %s %s %s", copiedCommand, copiedName, copiedContent)
+			}
+		})
+		toolResult, err = c.callTool("pods_cp_to", map[string]interface{}{
+			"namespace": "default", "name": "pod-to-cp", "path": "data.bin", "encoding": "base64",
+			"content": base64.StdEncoding.EncodeToString([]byte{0xCA, 0xFE, 0x00, 0xFF}),
+		})
+		t.Run("pods_cp_to with base64 content copies the decoded file", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			if copiedCommand != "tar xmf - -C ." || copiedName != "data.bin" || !bytes.Equal(copiedContent, []byte{0xCA, 0xFE, 0x00, 0xFF}) {
+				t.Fatalf("unexpected copy %s %s %v", copiedCommand, copiedName, copiedContent)
+			}
+		})
+		toolResult, _ = c.callTool("pods_cp_to", map[string]interface{}{
+			"namespace": "default", "name": "pod-to-cp", "path": "/tmp/data.bin", "encoding": "base64", "content": "not base64!",
+		})
+		t.Run("pods_cp_to with invalid base64 content returns error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.HasPrefix(toolResult.Content[0].(mcp.TextContent).Text,
+				"failed to copy /tmp/data.bin to pod pod-to-cp in namespace default, invalid base64 content") {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_cp_to", map[string]interface{}{
+			"namespace": "default", "name": "pod-to-cp", "path": "/tmp/large.txt", "content": strings.Repeat("a", 1024*1024+1),
+		})
+		t.Run("pods_cp_to with content exceeding the size limit returns error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.HasSuffix(toolResult.Content[0].(mcp.TextContent).Text, "content size 1048577 bytes exceeds the maximum of 1048576 bytes") {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_cp_to", map[string]interface{}{
+			"namespace": "default", "name": "pod-to-cp", "path": "/", "content": "a",
+		})
+		t.Run("pods_cp_to with invalid path returns error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.HasSuffix(toolResult.Content[0].(mcp.TextContent).Text, `invalid file path "/"`) {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+	})
+}
@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"bytes"
+	"github.com/mark3labs/mcp-go/mcp"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"net/http"
+	"net/url"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPodsDebug(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		pod := &v1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "distroless"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "gcr.io/distroless/static"}}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		}
+		podMutex := sync.Mutex{}
+		var execQuery url.Values
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			podMutex.Lock()
+			defer podMutex.Unlock()
+			switch {
+			case req.URL.Path == "/api/v1/namespaces/default/pods/distroless" && req.Method == http.MethodGet:
+				writeObject(w, pod)
+			case req.URL.Path == "/api/v1/namespaces/default/pods/distroless/ephemeralcontainers" && req.Method == http.MethodPut:
+				body, _ := io.ReadAll(req.Body)
+				updated := &v1.Pod{}
+				_, _, _ = scheme.Codecs.UniversalDeserializer().Decode(body, nil, updated)
+				pod.Spec.EphemeralContainers = updated.Spec.EphemeralContainers
+				// The ephemeral container is reported as running as soon as it's added
+				for _, container := range pod.Spec.EphemeralContainers {
+					state := v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+					if container.Image == "not-found" {
+						state = v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "image not found"}}
+					}
+					pod.Status.EphemeralContainerStatuses = append(pod.Status.EphemeralContainerStatuses, v1.ContainerStatus{Name: container.Name, State: state})
+				}
+				writeObject(w, pod)
+			}
+		}))
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/api/v1/namespaces/default/pods/distroless/exec" {
+				return
+			}
+			execQuery = req.URL.Query()
+			var stdout, stderr bytes.Buffer
+			ctx, err := createHTTPStreams(w, req, &StreamOptions{Stdout: &stdout, Stderr: &stderr})
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(err.Error()))
+				return
+			}
+			defer ctx.conn.Close()
+			_, _ = io.WriteString(ctx.stdoutStream, "PID USER COMMAND\n1 root /app\n")
+		}))
+		toolResult, err := c.callTool("pods_debug", map[string]interface{}{
+			"namespace": "default",
+			"name":      "distroless",
+			"command":   []interface{}{"ps"},
+			"target":    "app",
+		})
+		t.Run("pods_debug returns command output", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			var result map[string]interface{}
+			_ = yaml.Unmarshal([]byte(toolResult.Content[0].(mcp.TextContent).Text), &result)
+			if result["stdout"] != "PID USER COMMAND\n1 root /app\n" || result["exitCode"] != float64(0) ||
+				!strings.HasPrefix(result["container"].(string), "debugger-") {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("pods_debug adds ephemeral container with default image and target", func(t *testing.T) {
+			podMutex.Lock()
+			defer podMutex.Unlock()
+			if len(pod.Spec.EphemeralContainers) != 1 {
+				t.Fatalf("expected 1 ephemeral container, got %d", len(pod.Spec.EphemeralContainers))
+			}
+			ephemeralContainer := pod.Spec.EphemeralContainers[0]
+			if ephemeralContainer.Image != "busybox" || ephemeralContainer.TargetContainerName != "app" || !ephemeralContainer.Stdin {
+				t.Fatalf("unexpected ephemeral container %v", ephemeralContainer)
+			}
+		})
+		t.Run("pods_debug executes the command in the ephemeral container", func(t *testing.T) {
+			podMutex.Lock()
+			defer podMutex.Unlock()
+			if execQuery.Get("container") != pod.Spec.EphemeralContainers[0].Name || execQuery.Get("command") != "ps" {
+				t.Fatalf("unexpected exec query %v", execQuery)
+			}
+		})
+		toolResult, _ = c.callTool("pods_debug", map[string]interface{}{
+			"namespace": "default",
+			"name":      "distroless",
+			"command":   []interface{}{"ps"},
+			"image":     "not-found",
+		})
+		t.Run("pods_debug with image that can't be pulled returns error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.HasSuffix(toolResult.Content[0].(mcp.TextContent).Text, "can't be started: ErrImagePull image not found") {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_debug", map[string]interface{}{
+			"namespace": "default",
+			"name":      "distroless",
+			"command":   []interface{}{"ps"},
+			"target":    "not-found",
+		})
+		t.Run("pods_debug with unknown target returns error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.HasPrefix(toolResult.Content[0].(mcp.TextContent).Text,
+				"failed to debug pod distroless in namespace default: invalid target: container not-found not found in pod distroless, available containers: app") {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+	})
+}
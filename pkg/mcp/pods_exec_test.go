@@ -5,10 +5,16 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"io"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/remotecommand"
 	"net/http"
+	"net/url"
+	"sigs.k8s.io/yaml"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 func TestPodsExec(t *testing.T) {
@@ -16,23 +22,45 @@ func TestPodsExec(t *testing.T) {
 		mockServer := NewMockServer()
 		defer mockServer.Close()
 		c.withKubeConfig(mockServer.config)
+		var query url.Values
 		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			if req.URL.Path != "/api/v1/namespaces/default/pods/pod-to-exec/exec" {
 				return
 			}
-			var stdin, stdout bytes.Buffer
-			ctx, err := createHTTPStreams(w, req, &StreamOptions{
-				Stdin:  &stdin,
-				Stdout: &stdout,
-			})
+			query = req.URL.Query()
+			var stdin, stdout, stderr bytes.Buffer
+			streamOptions := &StreamOptions{Stdout: &stdout, Stderr: &stderr}
+			if query.Get("stdin") == "true" {
+				streamOptions.Stdin = &stdin
+			}
+			ctx, err := createHTTPStreams(w, req, streamOptions)
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				_, _ = w.Write([]byte(err.Error()))
 				return
 			}
 			defer ctx.conn.Close()
-			_, _ = io.WriteString(ctx.stdoutStream, strings.Join(req.URL.Query()["command"], " "))
-			_, _ = io.WriteString(ctx.stdoutStream, "\ntotal 0\n")
+			switch command := strings.Join(query["command"], " "); command {
+			case "cat":
+				input, _ := io.ReadAll(ctx.stdinStream)
+				_, _ = ctx.stdoutStream.Write(input)
+			case "ls /not-found":
+				_, _ = io.WriteString(ctx.stderrStream, "ls: /not-found: No such file or directory\n")
+				_ = ctx.writeStatus(&apierrors.StatusError{ErrStatus: metav1.Status{
+					Status: metav1.StatusFailure,
+					Reason: remotecommand.NonZeroExitCodeReason,
+					Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{
+						{Type: remotecommand.ExitCodeCauseType, Message: "2"},
+					}},
+				}})
+			case "yes":
+				_, _ = io.WriteString(ctx.stdoutStream, strings.Repeat("€", 100000))
+			case "sleep 1":
+				time.Sleep(1 * time.Second)
+			default:
+				_, _ = io.WriteString(ctx.stdoutStream, command)
+				_, _ = io.WriteString(ctx.stdoutStream, "\ntotal 0\n")
+			}
 		}))
 		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			if req.URL.Path != "/api/v1/namespaces/default/pods/pod-to-exec" {
@@ -43,9 +71,18 @@ func TestPodsExec(t *testing.T) {
 					Namespace: "default",
 					Name:      "pod-to-exec",
 				},
-				Spec: v1.PodSpec{Containers: []v1.Container{{Name: "container-to-exec"}}},
+				Spec: v1.PodSpec{
+					Containers:          []v1.Container{{Name: "container-to-exec"}, {Name: "sidecar"}},
+					InitContainers:      []v1.Container{{Name: "init"}},
+					EphemeralContainers: []v1.EphemeralContainer{{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debugger"}}},
+				},
 			})
 		}))
+		execResult := func(toolResult *mcp.CallToolResult) map[string]interface{} {
+			var result map[string]interface{}
+			_ = yaml.Unmarshal([]byte(toolResult.Content[0].(mcp.TextContent).Text), &result)
+			return result
+		}
 		toolResult, err := c.callTool("pods_exec", map[string]interface{}{
 			"namespace": "default",
 			"name":      "pod-to-exec",
@@ -58,10 +95,112 @@ func TestPodsExec(t *testing.T) {
 			if toolResult.IsError {
 				t.Fatalf("call tool failed")
 			}
-			if toolResult.Content[0].(mcp.TextContent).Text != "ls -l\ntotal 0\n" {
+			result := execResult(toolResult)
+			if result["stdout"] != "ls -l\ntotal 0\n" || result["stderr"] != "" || result["exitCode"] != float64(0) {
 				t.Errorf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
 			}
 		})
-
+		t.Run("pods_exec without container executes the command in the first container", func(t *testing.T) {
+			if query.Get("container") != "container-to-exec" || execResult(toolResult)["container"] != "container-to-exec" {
+				t.Errorf("unexpected container %v", query.Get("container"))
+			}
+		})
+		largeOutput, err := c.callTool("pods_exec", map[string]interface{}{
+			"namespace": "default",
+			"name":      "pod-to-exec",
+			"command":   []interface{}{"yes"},
+		})
+		t.Run("pods_exec with large output returns truncated output", func(t *testing.T) {
+			if err != nil || largeOutput.IsError {
+				t.Fatalf("call tool failed %v %v", err, largeOutput.Content)
+			}
+			result := execResult(largeOutput)
+			stdout, _ := result["stdout"].(string)
+			if len(stdout) != 65535 || !utf8.ValidString(stdout) || result["stdoutTruncated"] != true {
+				t.Errorf("expected stdout truncated at a rune boundary, got %d bytes %v", len(stdout), result["stdoutTruncated"])
+			}
+			if _, ok := result["stderrTruncated"]; ok {
+				t.Errorf("unexpected stderr truncation %v", result["stderrTruncated"])
+			}
+		})
+		for _, container := range []string{"sidecar", "init", "debugger"} {
+			toolResult, err = c.callTool("pods_exec", map[string]interface{}{
+				"namespace": "default",
+				"name":      "pod-to-exec",
+				"container": container,
+				"command":   []interface{}{"ls"},
+			})
+			t.Run("pods_exec with container "+container+" executes the command in the container", func(t *testing.T) {
+				if err != nil || toolResult.IsError {
+					t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+				}
+				if query.Get("container") != container {
+					t.Errorf("unexpected container %v", query.Get("container"))
+				}
+			})
+		}
+		toolResult, _ = c.callTool("pods_exec", map[string]interface{}{
+			"namespace": "default",
+			"name":      "pod-to-exec",
+			"container": "not-found",
+			"command":   []interface{}{"ls"},
+		})
+		t.Run("pods_exec with unknown container returns error", func(t *testing.T) {
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != "failed to exec in pod pod-to-exec in namespace default: "+
+				"container not-found not found in pod pod-to-exec, available containers: container-to-exec, sidecar, init, debugger" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, err = c.callTool("pods_exec", map[string]interface{}{
+			"namespace": "default",
+			"name":      "pod-to-exec",
+			"command":   []interface{}{"cat"},
+			"stdin":     "hello from stdin\n",
+		})
+		t.Run("pods_exec with stdin passes the input to the command", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			if query.Get("stdin") != "true" || execResult(toolResult)["stdout"] != "hello from stdin\n" {
+				t.Errorf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, err = c.callTool("pods_exec", map[string]interface{}{
+			"namespace": "default",
+			"name":      "pod-to-exec",
+			"command":   []interface{}{"ls", "/not-found"},
+		})
+		t.Run("pods_exec with failing command returns stderr and exit code", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			result := execResult(toolResult)
+			if result["exitCode"] != float64(2) || result["stderr"] != "ls: /not-found: No such file or directory\n" || result["stdout"] != "" {
+				t.Errorf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_exec", map[string]interface{}{
+			"namespace": "default",
+			"name":      "pod-to-exec",
+			"command":   []interface{}{"sleep", "1"},
+			"timeout":   0.1,
+		})
+		t.Run("pods_exec exceeding the timeout returns error", func(t *testing.T) {
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text !=
+				"failed to exec in pod pod-to-exec in namespace default: command timed out after 100ms: context deadline exceeded" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_exec", map[string]interface{}{
+			"namespace": "default",
+			"name":      "pod-to-exec",
+			"command":   []interface{}{"ls"},
+			"timeout":   3600,
+		})
+		t.Run("pods_exec with timeout exceeding the maximum returns error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.HasSuffix(toolResult.Content[0].(mcp.TextContent).Text, "timeout must be positive and must not exceed 10m0s") {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
 	})
 }
@@ -0,0 +1,320 @@
+package mcp
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPodsLogOptions(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		var query url.Values
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/api/v1/namespaces/default/pods/a-pod/log" {
+				return
+			}
+			query = req.URL.Query()
+			_, _ = w.Write([]byte("INFO starting\nERROR connection refused\nINFO retrying\nERROR timeout"))
+		}))
+		toolResult, err := c.callTool("pods_log", map[string]interface{}{
+			"namespace":    "default",
+			"name":         "a-pod",
+			"container":    "a-container",
+			"previous":     true,
+			"tail":         10,
+			"sinceSeconds": 3600,
+			"timestamps":   true,
+		})
+		t.Run("pods_log with options returns log", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			if toolResult.Content[0].(mcp.TextContent).Text != "INFO starting\nERROR connection refused\nINFO retrying\nERROR timeout\n" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("pods_log with options sends log options to the API server", func(t *testing.T) {
+			if query.Get("container") != "a-container" || query.Get("previous") != "true" || query.Get("tailLines") != "10" ||
+				query.Get("sinceSeconds") != "3600" || query.Get("timestamps") != "true" {
+				t.Fatalf("unexpected query %v", query)
+			}
+		})
+		_, _ = c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "a-pod"})
+		t.Run("pods_log without options retrieves the default tail lines", func(t *testing.T) {
+			if query.Get("tailLines") != "256" || query.Has("container") || query.Has("previous") {
+				t.Fatalf("unexpected query %v", query)
+			}
+		})
+		_, _ = c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "a-pod", "sinceTime": "2025-01-01T10:00:00Z"})
+		t.Run("pods_log with sinceTime retrieves all the lines since the provided time", func(t *testing.T) {
+			if query.Has("tailLines") || query.Get("sinceTime") != "2025-01-01T10:00:00Z" {
+				t.Fatalf("unexpected query %v", query)
+			}
+		})
+		toolResult, _ = c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "a-pod", "filter": "^ERROR"})
+		t.Run("pods_log with filter returns only matching lines", func(t *testing.T) {
+			if toolResult.IsError {
+				t.Fatalf("call tool failed %v", toolResult.Content)
+			}
+			if toolResult.Content[0].(mcp.TextContent).Text != "ERROR connection refused\nERROR timeout\n" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "a-pod", "filter": "^FATAL"})
+		t.Run("pods_log with filter without matches returns message", func(t *testing.T) {
+			if toolResult.Content[0].(mcp.TextContent).Text != "The pod a-pod in namespace default has not logged any message matching ^FATAL" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "a-pod", "filter": "[a-"})
+		t.Run("pods_log with invalid filter returns error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.HasPrefix(toolResult.Content[0].(mcp.TextContent).Text,
+				"failed to get pod a-pod log in namespace default: invalid filter regular expression") {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "a-pod", "sinceTime": "yesterday"})
+		t.Run("pods_log with invalid sinceTime returns error", func(t *testing.T) {
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text !=
+				"failed to get pod a-pod log in namespace default: invalid sinceTime yesterday, expected RFC3339 format" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_log", map[string]interface{}{
+			"namespace": "default", "name": "a-pod", "sinceSeconds": 60, "sinceTime": "2025-01-01T10:00:00Z",
+		})
+		t.Run("pods_log with sinceSeconds and sinceTime returns error", func(t *testing.T) {
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text !=
+				"failed to get pod a-pod log in namespace default: only one of sinceSeconds or sinceTime can be provided" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "a-pod", "tail": 10.5})
+		t.Run("pods_log with fractional tail returns error", func(t *testing.T) {
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text !=
+				"failed to get pod a-pod log in namespace default: invalid tail argument 10.5, must be an integer" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+	})
+}
+
+func TestPodsLogMaxBytes(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/api/v1/namespaces/default/pods/a-verbose-pod/log" {
+				return
+			}
+			for i := 0; i < 10000; i++ {
+				_, _ = w.Write([]byte("a verbose log line that is repeated many times\n"))
+			}
+			_, _ = w.Write([]byte("the last line\n"))
+		}))
+		toolResult, _ := c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "a-verbose-pod"})
+		text := toolResult.Content[0].(mcp.TextContent).Text
+		t.Run("pods_log with large log returns truncated log", func(t *testing.T) {
+			if len(text) > 65*1024 {
+				t.Fatalf("expected log to be truncated, got %d bytes", len(text))
+			}
+			if !strings.HasPrefix(text, "# The log exceeds 65536 bytes, only the most recent lines are returned\n") {
+				t.Fatalf("expected truncation message, got %s", text[:100])
+			}
+		})
+		t.Run("pods_log with large log keeps the most recent lines", func(t *testing.T) {
+			if !strings.HasSuffix(text, "the last line\n") {
+				t.Fatalf("expected last line, got %s", text[len(text)-100:])
+			}
+		})
+	})
+}
+
+func TestPodsLogLongLine(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		var query url.Values
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/api/v1/namespaces/default/pods/a-pod/log" {
+				return
+			}
+			query = req.URL.Query()
+			// A single line of 3-byte runes that doesn't end at a rune boundary once truncated to the maximum size
+			_, _ = w.Write([]byte("start " + strings.Repeat("€", 100000) + "\n"))
+		}))
+		toolResult, err := c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "a-pod"})
+		t.Run("pods_log limits the bytes retrieved from the API server", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			if query.Get("limitBytes") != strconv.Itoa(16*64*1024) {
+				t.Fatalf("expected limitBytes query parameter, got %v", query)
+			}
+		})
+		text := toolResult.Content[0].(mcp.TextContent).Text
+		t.Run("pods_log with long line returns truncated line", func(t *testing.T) {
+			if !strings.HasPrefix(text, "# The log exceeds 65536 bytes, only the most recent lines are returned\n") {
+				t.Fatalf("expected truncation message, got %s", text[:100])
+			}
+			if len(text) > 65*1024 || strings.Contains(text, "start") {
+				t.Fatalf("expected line to be truncated, got %d bytes", len(text))
+			}
+		})
+		t.Run("pods_log with long line cuts the line at a rune boundary", func(t *testing.T) {
+			if !utf8.ValidString(text) || !strings.HasSuffix(text, "€€€\n") {
+				t.Fatalf("expected valid UTF-8 line, got %q", text[len(text)-10:])
+			}
+		})
+	})
+}
+
+func TestPodsLogFollow(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		var query url.Values
+		// Writes the log lines and keeps the stream open until the client closes it (unless the pod is ended-pod)
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if !strings.HasPrefix(req.URL.Path, "/api/v1/namespaces/default/pods/") || !strings.HasSuffix(req.URL.Path, "/log") {
+				return
+			}
+			query = req.URL.Query()
+			_, _ = w.Write([]byte("INFO starting\nERROR connection refused\nINFO retrying\n"))
+			w.(http.Flusher).Flush()
+			if req.URL.Path != "/api/v1/namespaces/default/pods/ended-pod/log" {
+				<-req.Context().Done()
+			}
+		}))
+		var notifications []mcp.JSONRPCNotification
+		notificationsMutex := sync.Mutex{}
+		firstNotification := make(chan struct{}, 10)
+		c.mcpClient.OnNotification(func(notification mcp.JSONRPCNotification) {
+			if notification.Method != "notifications/progress" {
+				return
+			}
+			notificationsMutex.Lock()
+			defer notificationsMutex.Unlock()
+			notifications = append(notifications, notification)
+			firstNotification <- struct{}{}
+		})
+		callToolWithProgress := func(args map[string]interface{}, token string) (*mcp.CallToolResult, error) {
+			callToolRequest := mcp.CallToolRequest{}
+			callToolRequest.Params.Name = "pods_log"
+			callToolRequest.Params.Arguments = args
+			callToolRequest.Params.Meta = &mcp.Meta{ProgressToken: token}
+			return c.mcpClient.CallTool(c.ctx, callToolRequest)
+		}
+		toolResult, err := c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "ended-pod", "follow": true})
+		t.Run("pods_log with follow and without progress token returns the lines until the stream ends", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			text := toolResult.Content[0].(mcp.TextContent).Text
+			if !strings.HasPrefix(text, "# Followed the logs of pod ended-pod in namespace default for ") ||
+				!strings.HasSuffix(text, ", 3 lines streamed, stopped because the log stream ended\nINFO starting\nERROR connection refused\nINFO retrying\n") {
+				t.Fatalf("unexpected result %v", text)
+			}
+		})
+		t.Run("pods_log with follow streams only the new lines", func(t *testing.T) {
+			if query.Get("follow") != "true" || query.Get("tailLines") != "0" {
+				t.Fatalf("unexpected query %v", query)
+			}
+		})
+		toolResult, err = callToolWithProgress(map[string]interface{}{
+			"namespace": "default", "name": "a-pod", "follow": true, "followLines": 2, "filter": "connection|retrying",
+		}, "line-limit")
+		t.Run("pods_log with follow and progress token stops at the line limit", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			text := toolResult.Content[0].(mcp.TextContent).Text
+			if !strings.HasSuffix(text, ", 2 lines streamed, stopped because the line limit of 2 was reached (the lines were sent as progress notifications)") {
+				t.Fatalf("unexpected result %v", text)
+			}
+		})
+		t.Run("pods_log with follow and progress token sends the lines as progress notifications", func(t *testing.T) {
+			notificationsMutex.Lock()
+			defer notificationsMutex.Unlock()
+			if len(notifications) != 2 {
+				t.Fatalf("expected 2 notifications, got %d", len(notifications))
+			}
+			for i, expected := range []string{"ERROR connection refused", "INFO retrying"} {
+				params := notifications[i].Params.AdditionalFields
+				if params["progressToken"] != "line-limit" || params["progress"] != float64(i+1) || params["message"] != expected {
+					t.Fatalf("unexpected notification %v", params)
+				}
+			}
+		})
+		toolResult, err = callToolWithProgress(map[string]interface{}{
+			"namespace": "default", "name": "a-pod", "follow": true, "followSeconds": 0.5,
+		}, "time-limit")
+		t.Run("pods_log with follow stops at the time limit", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			text := toolResult.Content[0].(mcp.TextContent).Text
+			if !strings.Contains(text, ", 3 lines streamed, stopped because the time limit of 500ms was reached") {
+				t.Fatalf("unexpected result %v", text)
+			}
+		})
+		notificationsMutex.Lock()
+		notifications = nil
+		firstNotification = make(chan struct{}, 10)
+		notificationsMutex.Unlock()
+		go func() {
+			<-firstNotification
+			c.mcpServer.cancellations.mu.Lock()
+			var requestID string
+			for requestKey, progressKey := range c.mcpServer.cancellations.progressTokens {
+				if strings.HasSuffix(progressKey, "/cancelled") {
+					requestID = requestKey[strings.LastIndex(requestKey, "/")+1:]
+				}
+			}
+			c.mcpServer.cancellations.mu.Unlock()
+			id, _ := strconv.Atoi(requestID)
+			_ = c.mcpClient.GetTransport().SendNotification(c.ctx, mcp.JSONRPCNotification{
+				JSONRPC: mcp.JSONRPC_VERSION,
+				Notification: mcp.Notification{
+					Method: "notifications/cancelled",
+					Params: mcp.NotificationParams{AdditionalFields: map[string]any{"requestId": id, "reason": "test"}},
+				},
+			})
+		}()
+		toolResult, err = callToolWithProgress(map[string]interface{}{"namespace": "default", "name": "a-pod", "follow": true}, "cancelled")
+		t.Run("pods_log with follow stops when the client cancels the request", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			text := toolResult.Content[0].(mcp.TextContent).Text
+			if !strings.Contains(text, "stopped because the request was cancelled") {
+				t.Fatalf("unexpected result %v", text)
+			}
+		})
+		t.Run("pods_log with follow releases the cancellation of the completed requests", func(t *testing.T) {
+			c.mcpServer.cancellations.mu.Lock()
+			defer c.mcpServer.cancellations.mu.Unlock()
+			if len(c.mcpServer.cancellations.progressTokens) != 0 || len(c.mcpServer.cancellations.cancels) != 0 {
+				t.Fatalf("unexpected in-flight requests %v %v", c.mcpServer.cancellations.progressTokens, c.mcpServer.cancellations.cancels)
+			}
+		})
+		toolResult, _ = c.callTool("pods_log", map[string]interface{}{"namespace": "default", "name": "a-pod", "follow": true, "followSeconds": 3600})
+		t.Run("pods_log with follow and followSeconds exceeding the maximum returns error", func(t *testing.T) {
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text !=
+				"failed to follow pod a-pod log in namespace default: followSeconds must not exceed 300" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+	})
+}
@@ -0,0 +1,327 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"io"
+	"k8s.io/klog/v2"
+	"net/http"
+	"sigs.k8s.io/yaml"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// portForwardsMaxPerSession is the maximum number of active port-forwards of an MCP session
+	portForwardsMaxPerSession = 10
+	// httpGetTimeout is the maximum duration of the http_get requests
+	httpGetTimeout = 30 * time.Second
+	// httpGetMaxBytes is the maximum size of the http_get response body returned to the model
+	httpGetMaxBytes = 64 * 1024
+)
+
+// portForward is an active port-forward owned by an MCP session
+type portForward struct {
+	ID string `json:"id"`
+	*kubernetes.PortForward
+
+	session  string
+	sequence int
+}
+
+// portForwards tracks the active port-forwards of the MCP sessions.
+// The port-forwards outlive the tool calls that started them, they are stopped by the client (pods_port_forward_stop),
+// when the MCP session ends, or when the server is closed.
+type portForwards struct {
+	mu       sync.Mutex
+	sequence int
+	forwards map[string]*portForward
+}
+
+func newPortForwards() *portForwards {
+	return &portForwards{forwards: make(map[string]*portForward)}
+}
+
+// addHooks stops the port-forwards of the MCP sessions when they end
+func (p *portForwards) addHooks(hooks *server.Hooks) {
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		p.stopSession(session.SessionID())
+	})
+}
+
+// add registers the port-forward of the session, it's unregistered once the port-forward terminates
+func (p *portForwards) add(session string, pf *kubernetes.PortForward) (*portForward, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.sessionForwards(session)) >= portForwardsMaxPerSession {
+		return nil, fmt.Errorf("the maximum of %d active port-forwards was reached, stop some of them first", portForwardsMaxPerSession)
+	}
+	p.sequence++
+	forward := &portForward{
+		ID:          "pf-" + strconv.Itoa(p.sequence),
+		PortForward: pf,
+		session:     session,
+		sequence:    p.sequence,
+	}
+	p.forwards[forward.ID] = forward
+	go func() {
+		<-pf.Done()
+		if err := pf.Err(); err != nil {
+			klog.V(1).InfoS("Port-forward terminated", "id", forward.ID, "sessionId", session, "target", pf.Target, "error", err)
+		}
+		p.remove(forward)
+	}()
+	return forward, nil
+}
+
+func (p *portForwards) remove(forward *portForward) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.forwards, forward.ID)
+}
+
+// get returns the active port-forward of the session with the provided id
+func (p *portForwards) get(session, id string) (*portForward, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	forward, found := p.forwards[id]
+	if !found || forward.session != session {
+		return nil, fmt.Errorf("port-forward %s not found (use pods_port_forward_list to list the active port-forwards)", id)
+	}
+	return forward, nil
+}
+
+// list returns the active port-forwards of the session sorted by creation
+func (p *portForwards) list(session string) []*portForward {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sessionForwards(session)
+}
+
+func (p *portForwards) stop(session, id string) error {
+	forward, err := p.get(session, id)
+	if err != nil {
+		return err
+	}
+	forward.Stop()
+	p.remove(forward)
+	return nil
+}
+
+func (p *portForwards) stopSession(session string) {
+	for _, forward := range p.list(session) {
+		forward.Stop()
+		p.remove(forward)
+	}
+}
+
+func (p *portForwards) stopAll() {
+	p.mu.Lock()
+	forwards := make([]*portForward, 0, len(p.forwards))
+	for _, forward := range p.forwards {
+		forwards = append(forwards, forward)
+	}
+	p.mu.Unlock()
+	for _, forward := range forwards {
+		forward.Stop()
+		p.remove(forward)
+	}
+}
+
+// sessionForwards must be called with the lock held
+func (p *portForwards) sessionForwards(session string) []*portForward {
+	forwards := make([]*portForward, 0)
+	for _, forward := range p.forwards {
+		if forward.session == session {
+			forwards = append(forwards, forward)
+		}
+	}
+	slices.SortFunc(forwards, func(a, b *portForward) int {
+		return a.sequence - b.sequence
+	})
+	return forwards
+}
+
+func (s *Server) initPortForward() []server.ServerTool {
+	return []server.ServerTool{
+		{Tool: mcp.NewTool("pods_port_forward_start",
+			mcp.WithDescription("Start forwarding a local port of the MCP server host to a port of a Kubernetes Pod, or of a running Pod selected by a Service, "+
+				"in the current or provided namespace (as kubectl port-forward). "+
+				"The port-forward remains active until it's stopped with pods_port_forward_stop or the MCP session ends, use http_get to probe HTTP endpoints through it. "+
+				fmt.Sprintf("Returns the id of the port-forward and the local address it listens on (maximum %d active port-forwards per session)", portForwardsMaxPerSession)),
+			mcp.WithString("namespace", mcp.Description("Namespace of the Pod or Service to forward the port to")),
+			mcp.WithString("type", mcp.Description("Type of the port-forward target (Optional, defaults to pod)"),
+				mcp.Enum(kubernetes.PortForwardTargetPod, kubernetes.PortForwardTargetService)),
+			mcp.WithString("name", mcp.Description("Name of the Pod or Service to forward the port to"), mcp.Required()),
+			mcp.WithNumber("port", mcp.Description("Port of the Pod, or port of the Service (mapped to the target port of the selected Pod)"), mcp.Required()),
+			mcp.WithNumber("localPort", mcp.Description("Local port to listen on 127.0.0.1 (Optional, defaults to a random available port)")),
+			withContext(),
+			mcp.WithTitleAnnotation("Pods: Port Forward Start"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.portForwardStart},
+		{Tool: mcp.NewTool("pods_port_forward_list",
+			mcp.WithDescription("List the active port-forwards started in the current MCP session with pods_port_forward_start"),
+			mcp.WithTitleAnnotation("Pods: Port Forward List"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.portForwardList},
+		{Tool: mcp.NewTool("pods_port_forward_stop",
+			mcp.WithDescription("Stop an active port-forward started in the current MCP session with pods_port_forward_start"),
+			mcp.WithString("id", mcp.Description("Id of the port-forward to stop"), mcp.Required()),
+			mcp.WithTitleAnnotation("Pods: Port Forward Stop"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+		), Handler: s.portForwardStop},
+		{Tool: mcp.NewTool("http_get",
+			mcp.WithDescription("Perform an HTTP GET request through an active port-forward started with pods_port_forward_start, "+
+				"useful to probe the endpoints of an application (e.g. health checks or metrics). "+
+				fmt.Sprintf("Returns the response status, headers, and body (truncated to %d bytes)", httpGetMaxBytes)),
+			mcp.WithString("id", mcp.Description("Id of the port-forward to send the request through"), mcp.Required()),
+			mcp.WithString("path", mcp.Description("Path and query of the request, e.g. /healthz (Optional, defaults to /)")),
+			mcp.WithTitleAnnotation("HTTP: Get"),
+			// Not read-only: the request reaches the application itself (not the Kubernetes API), whose GET endpoints
+			// aren't guaranteed to be free of side effects (e.g. admin or debug endpoints)
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.httpGet},
+	}
+}
+
+func (s *Server) portForwardStart(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns := ctr.GetArguments()["namespace"]
+	if ns == nil {
+		ns = ""
+	}
+	targetType := kubernetes.PortForwardTargetPod
+	if t, ok := ctr.GetArguments()["type"].(string); ok && t != "" {
+		targetType = t
+	}
+	name := ctr.GetArguments()["name"]
+	if name == nil {
+		return NewTextResult(ctx, "", errors.New("failed to start port-forward, missing argument name")), nil
+	}
+	port, err := integerArgument(ctr.GetArguments(), "port")
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to start port-forward, %s", err)), nil
+	}
+	if port == nil {
+		return NewTextResult(ctx, "", errors.New("failed to start port-forward, missing argument port")), nil
+	}
+	localPort := 0
+	if lp, err := integerArgument(ctr.GetArguments(), "localPort"); err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to start port-forward, %s", err)), nil
+	} else if lp != nil {
+		localPort = int(*lp)
+	}
+	session := sessionID(ctx)
+	if len(s.portForwards.list(session)) >= portForwardsMaxPerSession {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to start port-forward: the maximum of %d active port-forwards was reached, stop some of them first", portForwardsMaxPerSession)), nil
+	}
+	pf, err := k.PortForwardStart(ctx, ns.(string), targetType, name.(string), localPort, int(*port))
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to start port-forward to %s %s in namespace %s: %w", targetType, name, ns, err)), nil
+	}
+	forward, err := s.portForwards.add(session, pf)
+	if err != nil {
+		pf.Stop()
+		return NewTextResult(ctx, "", fmt.Errorf("failed to start port-forward: %w", err)), nil
+	}
+	ret, err := yaml.Marshal(forward)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to start port-forward: %w", err)), nil
+	}
+	return NewTextResult(ctx, fmt.Sprintf("Port-forward %s started, listening on %s:\n%s", forward.ID, forward.LocalAddress, ret), nil), nil
+}
+
+func (s *Server) portForwardList(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	forwards := s.portForwards.list(sessionID(ctx))
+	if len(forwards) == 0 {
+		return NewTextResult(ctx, "No active port-forwards found", nil), nil
+	}
+	ret, err := yaml.Marshal(forwards)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to list port-forwards: %w", err)), nil
+	}
+	return NewTextResult(ctx, fmt.Sprintf("The following port-forwards (YAML format) are active:\n%s", ret), nil), nil
+}
+
+func (s *Server) portForwardStop(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, ok := ctr.GetArguments()["id"].(string)
+	if !ok {
+		return NewTextResult(ctx, "", errors.New("failed to stop port-forward, missing argument id")), nil
+	}
+	if err := s.portForwards.stop(sessionID(ctx), id); err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to stop port-forward: %w", err)), nil
+	}
+	return NewTextResult(ctx, fmt.Sprintf("Port-forward %s stopped", id), nil), nil
+}
+
+// httpGetResult is the response of an http_get request
+type httpGetResult struct {
+	Status    string            `json:"status"`
+	Headers   map[string]string `json:"headers"`
+	Body      string            `json:"body"`
+	Truncated bool              `json:"truncated,omitempty"`
+}
+
+func (s *Server) httpGet(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, ok := ctr.GetArguments()["id"].(string)
+	if !ok {
+		return NewTextResult(ctx, "", errors.New("failed to perform HTTP GET, missing argument id")), nil
+	}
+	path := "/"
+	if p, ok := ctr.GetArguments()["path"].(string); ok && p != "" {
+		path = p
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	forward, err := s.portForwards.get(sessionID(ctx), id)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to perform HTTP GET: %w", err)), nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, httpGetTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+forward.LocalAddress+path, nil)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to perform HTTP GET: %w", err)), nil
+	}
+	// Redirects are returned to the model (they might point outside the port-forward)
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Do(req)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to perform HTTP GET %s through port-forward %s: %w", path, id, err)), nil
+	}
+	defer func() { _ = res.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(res.Body, httpGetMaxBytes+1))
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to read HTTP GET %s response: %w", path, err)), nil
+	}
+	result := &httpGetResult{Status: res.Status, Headers: make(map[string]string)}
+	for header := range res.Header {
+		result.Headers[header] = res.Header.Get(header)
+	}
+	if len(body) > httpGetMaxBytes {
+		body = body[:httpGetMaxBytes]
+		result.Truncated = true
+	}
+	result.Body = string(body)
+	ret, err := yaml.Marshal(result)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to perform HTTP GET: %w", err)), nil
+	}
+	return NewTextResult(ctx, string(ret), nil), nil
+}
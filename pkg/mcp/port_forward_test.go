@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/mark3labs/mcp-go/mcp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net"
+	"net/http"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"testing"
+	"time"
+)
+
+// handlePortForward serves the portforward subresource of the Pod, the forwarded port responds to HTTP requests with
+// the request line and the Pod port
+func handlePortForward(w http.ResponseWriter, req *http.Request) {
+	if _, err := httpstream.Handshake(req, w, []string{"portforward.k8s.io"}); err != nil {
+		return
+	}
+	errorStreams := make(map[string]httpstream.Stream)
+	_ = spdy.NewResponseUpgrader().UpgradeResponse(w, req, func(stream httpstream.Stream, _ <-chan struct{}) error {
+		requestID := stream.Headers().Get(v1.PortForwardRequestIDHeader)
+		if stream.Headers().Get(v1.StreamType) == v1.StreamTypeError {
+			errorStreams[requestID] = stream
+			return nil
+		}
+		errorStream := errorStreams[requestID]
+		go func() {
+			defer func() {
+				_ = stream.Close()
+				if errorStream != nil {
+					_ = errorStream.Close()
+				}
+			}()
+			r, err := http.ReadRequest(bufio.NewReader(stream))
+			if err != nil {
+				return
+			}
+			body := fmt.Sprintf("%s %s port %s", r.Method, r.URL.RequestURI(), stream.Headers().Get(v1.PortHeader))
+			_, _ = fmt.Fprintf(stream, "HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nConnection: close\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+		}()
+		return nil
+	})
+}
+
+func TestPortForward(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/api/v1/namespaces/default/pods/app":
+				writeObject(w, &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+					Status:     v1.PodStatus{Phase: v1.PodRunning},
+				})
+			case "/api/v1/namespaces/default/pods/pending":
+				writeObject(w, &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending"},
+					Status:     v1.PodStatus{Phase: v1.PodPending},
+				})
+			case "/api/v1/namespaces/default/pods/app/portforward":
+				handlePortForward(w, req)
+			}
+		}))
+		toolResult, err := c.callTool("pods_port_forward_start", map[string]interface{}{
+			"namespace": "default",
+			"name":      "app",
+			"port":      8080,
+		})
+		t.Run("pods_port_forward_start starts port-forward", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			text := toolResult.Content[0].(mcp.TextContent).Text
+			if !strings.HasPrefix(text, "Port-forward pf-1 started, listening on 127.0.0.1:") {
+				t.Fatalf("unexpected result %v", text)
+			}
+			var result map[string]interface{}
+			if err = yaml.Unmarshal([]byte(text[strings.Index(text, "\n")+1:]), &result); err != nil {
+				t.Fatalf("invalid tool result content %v", err)
+			}
+			if result["id"] != "pf-1" || result["target"] != "pod/app" || result["pod"] != "app" || result["remotePort"] != float64(8080) {
+				t.Fatalf("unexpected port-forward %v", result)
+			}
+			if !strings.HasPrefix(result["localAddress"].(string), "127.0.0.1:") || result["localPort"] == float64(0) {
+				t.Fatalf("unexpected local address %v", result)
+			}
+		})
+		t.Run("http_get returns response through port-forward", func(t *testing.T) {
+			httpGet, err := c.callTool("http_get", map[string]interface{}{"id": "pf-1", "path": "/healthz?verbose"})
+			if err != nil || httpGet.IsError {
+				t.Fatalf("call tool failed %v %v", err, httpGet.Content)
+			}
+			var result map[string]interface{}
+			_ = yaml.Unmarshal([]byte(httpGet.Content[0].(mcp.TextContent).Text), &result)
+			if result["status"] != "200 OK" || result["body"] != "GET /healthz?verbose port 8080" {
+				t.Fatalf("unexpected response %v", result)
+			}
+			if result["headers"].(map[string]interface{})["Content-Type"] != "text/plain" {
+				t.Fatalf("unexpected headers %v", result["headers"])
+			}
+		})
+		t.Run("pods_port_forward_list lists active port-forwards", func(t *testing.T) {
+			list, err := c.callTool("pods_port_forward_list", map[string]interface{}{})
+			if err != nil || list.IsError {
+				t.Fatalf("call tool failed %v %v", err, list.Content)
+			}
+			text := list.Content[0].(mcp.TextContent).Text
+			if !strings.HasPrefix(text, "The following port-forwards (YAML format) are active:\n") {
+				t.Fatalf("unexpected result %v", text)
+			}
+			var result []map[string]interface{}
+			_ = yaml.Unmarshal([]byte(text[strings.Index(text, "\n")+1:]), &result)
+			if len(result) != 1 || result[0]["id"] != "pf-1" || result[0]["namespace"] != "default" {
+				t.Fatalf("unexpected port-forwards %v", result)
+			}
+		})
+		stop, err := c.callTool("pods_port_forward_stop", map[string]interface{}{"id": "pf-1"})
+		t.Run("pods_port_forward_stop stops port-forward", func(t *testing.T) {
+			if err != nil || stop.IsError {
+				t.Fatalf("call tool failed %v %v", err, stop.Content)
+			}
+			if stop.Content[0].(mcp.TextContent).Text != "Port-forward pf-1 stopped" {
+				t.Fatalf("unexpected result %v", stop.Content[0].(mcp.TextContent).Text)
+			}
+			list, _ := c.callTool("pods_port_forward_list", map[string]interface{}{})
+			if list.Content[0].(mcp.TextContent).Text != "No active port-forwards found" {
+				t.Fatalf("unexpected port-forwards %v", list.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("http_get with stopped port-forward returns error", func(t *testing.T) {
+			httpGet, _ := c.callTool("http_get", map[string]interface{}{"id": "pf-1"})
+			if !httpGet.IsError || httpGet.Content[0].(mcp.TextContent).Text !=
+				"failed to perform HTTP GET: port-forward pf-1 not found (use pods_port_forward_list to list the active port-forwards)" {
+				t.Fatalf("unexpected result %v", httpGet.Content)
+			}
+		})
+		t.Run("pods_port_forward_start with pod not running returns error", func(t *testing.T) {
+			pending, _ := c.callTool("pods_port_forward_start", map[string]interface{}{"namespace": "default", "name": "pending", "port": 8080})
+			if !pending.IsError || pending.Content[0].(mcp.TextContent).Text !=
+				"failed to start port-forward to pod pending in namespace default: unable to forward port because pod pending is not running, current phase is Pending" {
+				t.Fatalf("unexpected result %v", pending.Content)
+			}
+		})
+		t.Run("pods_port_forward_start with invalid port returns error", func(t *testing.T) {
+			invalid, _ := c.callTool("pods_port_forward_start", map[string]interface{}{"namespace": "default", "name": "app", "port": 70000})
+			if !invalid.IsError || !strings.HasSuffix(invalid.Content[0].(mcp.TextContent).Text, "ports must be between 1 and 65535 (local port 0 selects a random port)") {
+				t.Fatalf("unexpected result %v", invalid.Content)
+			}
+		})
+		t.Run("pods_port_forward_start with fractional port returns error", func(t *testing.T) {
+			fractional, _ := c.callTool("pods_port_forward_start", map[string]interface{}{"namespace": "default", "name": "app", "port": 8080.5})
+			if !fractional.IsError || fractional.Content[0].(mcp.TextContent).Text != "failed to start port-forward, invalid port argument 8080.5, must be an integer" {
+				t.Fatalf("unexpected result %v", fractional.Content)
+			}
+		})
+	})
+}
+
+func TestPortForwardService(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/api/v1/namespaces/default/services/web":
+				writeObject(w, &v1.Service{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+					Spec: v1.ServiceSpec{
+						Selector: map[string]string{"app": "web"},
+						Ports:    []v1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromString("http")}},
+					},
+				})
+			case "/api/v1/namespaces/default/pods":
+				if req.URL.Query().Get("labelSelector") != "app=web" {
+					writeObject(w, &v1.PodList{})
+					return
+				}
+				writeObject(w, &v1.PodList{Items: []v1.Pod{
+					{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-terminating", DeletionTimestamp: &metav1.Time{Time: time.Now()}},
+						Status:     v1.PodStatus{Phase: v1.PodRunning},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1"},
+						Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web", Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 8080}}}}},
+						Status:     v1.PodStatus{Phase: v1.PodRunning},
+					},
+				}})
+			case "/api/v1/namespaces/default/pods/web-1/portforward":
+				handlePortForward(w, req)
+			}
+		}))
+		toolResult, err := c.callTool("pods_port_forward_start", map[string]interface{}{
+			"namespace": "default",
+			"type":      "service",
+			"name":      "web",
+			"port":      80,
+		})
+		t.Run("pods_port_forward_start with service forwards to target port of selected pod", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			text := toolResult.Content[0].(mcp.TextContent).Text
+			var result map[string]interface{}
+			_ = yaml.Unmarshal([]byte(text[strings.Index(text, "\n")+1:]), &result)
+			if result["target"] != "service/web" || result["pod"] != "web-1" || result["remotePort"] != float64(8080) {
+				t.Fatalf("unexpected port-forward %v", result)
+			}
+			httpGet, _ := c.callTool("http_get", map[string]interface{}{"id": result["id"]})
+			if httpGet.IsError || !strings.Contains(httpGet.Content[0].(mcp.TextContent).Text, "body: GET / port 8080") {
+				t.Fatalf("unexpected response %v", httpGet.Content)
+			}
+		})
+		t.Run("pods_port_forward_start with service port not exposed returns error", func(t *testing.T) {
+			notExposed, _ := c.callTool("pods_port_forward_start", map[string]interface{}{"namespace": "default", "type": "service", "name": "web", "port": 443})
+			if !notExposed.IsError || notExposed.Content[0].(mcp.TextContent).Text !=
+				"failed to start port-forward to service web in namespace default: service web does not expose port 443" {
+				t.Fatalf("unexpected result %v", notExposed.Content)
+			}
+		})
+		t.Run("ending the MCP session stops its port-forwards", func(t *testing.T) {
+			var session, localAddress string
+			c.mcpServer.portForwards.mu.Lock()
+			for _, forward := range c.mcpServer.portForwards.forwards {
+				session, localAddress = forward.session, forward.LocalAddress
+			}
+			c.mcpServer.portForwards.mu.Unlock()
+			c.mcpServer.server.UnregisterSession(c.ctx, session)
+			if len(c.mcpServer.portForwards.list(session)) != 0 {
+				t.Fatalf("port-forwards of the session were not stopped")
+			}
+			if conn, err := net.DialTimeout("tcp", localAddress, time.Second); err == nil {
+				_ = conn.Close()
+				t.Fatalf("port-forward %s is still listening", localAddress)
+			}
+		})
+	})
+}
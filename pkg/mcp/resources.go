@@ -4,19 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"time"
 )
 
 func (s *Server) initResources() []server.ServerTool {
 	commonApiVersion := "v1 Pod, v1 Service, apps/v1 Deployment, networking.k8s.io/v1 Ingress"
-	if s.k.IsOpenShift(context.Background()) {
+	if s.k.Load().IsOpenShift(context.Background()) {
 		commonApiVersion += ", route.openshift.io/v1 Route"
 	}
 	commonApiVersion = fmt.Sprintf("(common apiVersion and kind include: %s)", commonApiVersion)
 	return []server.ServerTool{
-		{mcp.NewTool("resources_list",
+		{Tool: mcp.NewTool("resources_list",
 			mcp.WithDescription("List Kubernetes resources and objects in the current cluster by providing their apiVersion and kind and optionally the namespace\n"+
 				commonApiVersion),
 			mcp.WithString("apiVersion",
@@ -28,8 +30,13 @@ func (s *Server) initResources() []server.ServerTool {
 				mcp.Required(),
 			),
 			mcp.WithString("namespace",
-				mcp.Description("Optional Namespace to retrieve the namespaced resources from (ignored in case of cluster scoped resources). If not provided, will list resources from all namespaces"))), s.resourcesList},
-		{mcp.NewTool("resources_get",
+				mcp.Description("Optional Namespace to retrieve the namespaced resources from (ignored in case of cluster scoped resources). If not provided, will list resources from all namespaces")),
+			withContext(),
+			mcp.WithTitleAnnotation("Resources: List"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.resourcesList},
+		{Tool: mcp.NewTool("resources_get",
 			mcp.WithDescription("Get a Kubernetes resource in the current cluster by providing its apiVersion, kind, optionally the namespace, and its name\n"+
 				commonApiVersion),
 			mcp.WithString("apiVersion",
@@ -44,16 +51,25 @@ func (s *Server) initResources() []server.ServerTool {
 				mcp.Description("Optional Namespace to retrieve the namespaced resource from (ignored in case of cluster scoped resources). If not provided, will get resource from configured namespace"),
 			),
 			mcp.WithString("name", mcp.Description("Name of the resource"), mcp.Required()),
-		), s.resourcesGet},
-		{mcp.NewTool("resources_create_or_update",
+			withContext(),
+			mcp.WithTitleAnnotation("Resources: Get"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.resourcesGet},
+		{Tool: mcp.NewTool("resources_create_or_update",
 			mcp.WithDescription("Create or update a Kubernetes resource in the current cluster by providing a YAML or JSON representation of the resource\n"+
 				commonApiVersion),
 			mcp.WithString("resource",
 				mcp.Description("A JSON or YAML containing a representation of the Kubernetes resource. Should include top-level fields such as apiVersion,kind,metadata, and spec"),
 				mcp.Required(),
 			),
-		), s.resourcesCreateOrUpdate},
-		{mcp.NewTool("resources_delete",
+			withContext(),
+			mcp.WithTitleAnnotation("Resources: Create or Update"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		), Handler: s.resourcesCreateOrUpdate},
+		{Tool: mcp.NewTool("resources_delete",
 			mcp.WithDescription("Delete a Kubernetes resource in the current cluster by providing its apiVersion, kind, optionally the namespace, and its name\n"+
 				commonApiVersion),
 			mcp.WithString("apiVersion",
@@ -68,76 +84,157 @@ func (s *Server) initResources() []server.ServerTool {
 				mcp.Description("Optional Namespace to delete the namespaced resource from (ignored in case of cluster scoped resources). If not provided, will delete resource from configured namespace"),
 			),
 			mcp.WithString("name", mcp.Description("Name of the resource"), mcp.Required()),
-		), s.resourcesDelete},
+			withContext(),
+			mcp.WithTitleAnnotation("Resources: Delete"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		), Handler: s.resourcesDelete},
+		{Tool: mcp.NewTool("resources_scale",
+			mcp.WithDescription("Get or set the number of replicas of a Kubernetes resource in the current cluster through its scale subresource "+
+				"(Deployments, StatefulSets, ReplicaSets, or any custom resource exposing a scale subresource) by providing its apiVersion, kind, optionally the namespace, and its name. "+
+				"Returns the previous and the desired number of replicas, and the current and ready replicas"),
+			mcp.WithString("apiVersion",
+				mcp.Description("apiVersion of the resource (examples of valid apiVersion are: apps/v1)"),
+				mcp.Required(),
+			),
+			mcp.WithString("kind",
+				mcp.Description("kind of the resource (examples of valid kind are: Deployment, StatefulSet, ReplicaSet)"),
+				mcp.Required(),
+			),
+			mcp.WithString("namespace",
+				mcp.Description("Optional Namespace of the resource. If not provided, will scale resource from configured namespace"),
+			),
+			mcp.WithString("name", mcp.Description("Name of the resource"), mcp.Required()),
+			mcp.WithNumber("replicas", mcp.Description("Desired number of replicas (Optional, the current scale is returned without changes if not provided)")),
+			mcp.WithBoolean("wait", mcp.Description("Wait until the ready replicas match the desired replicas (Optional, defaults to false)")),
+			mcp.WithNumber("timeout", mcp.Description(fmt.Sprintf("Maximum number of seconds to wait for the ready replicas (Optional, defaults to %d, maximum %d)",
+				int(kubernetes.ResourcesScaleDefaultTimeout.Seconds()), int(kubernetes.ResourcesScaleMaxTimeout.Seconds())))),
+			withContext(),
+			mcp.WithTitleAnnotation("Resources: Scale"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		), Handler: s.resourcesScale},
 	}
 }
 
 func (s *Server) resourcesList(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace := ctr.Params.Arguments["namespace"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	namespace := ctr.GetArguments()["namespace"]
 	if namespace == nil {
 		namespace = ""
 	}
-	gvk, err := parseGroupVersionKind(ctr.Params.Arguments)
+	gvk, err := parseGroupVersionKind(ctr.GetArguments())
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to list resources, %s", err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to list resources, %s", err)), nil
 	}
-	ret, err := s.k.ResourcesList(ctx, gvk, namespace.(string))
+	ret, err := k.ResourcesList(ctx, gvk, namespace.(string))
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to list resources: %v", err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to list resources: %w", err)), nil
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
 }
 
 func (s *Server) resourcesGet(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace := ctr.Params.Arguments["namespace"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	namespace := ctr.GetArguments()["namespace"]
 	if namespace == nil {
 		namespace = ""
 	}
-	gvk, err := parseGroupVersionKind(ctr.Params.Arguments)
+	gvk, err := parseGroupVersionKind(ctr.GetArguments())
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to get resource, %s", err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get resource, %s", err)), nil
 	}
-	name := ctr.Params.Arguments["name"]
+	name := ctr.GetArguments()["name"]
 	if name == nil {
-		return NewTextResult("", errors.New("failed to get resource, missing argument name")), nil
+		return NewTextResult(ctx, "", errors.New("failed to get resource, missing argument name")), nil
 	}
-	ret, err := s.k.ResourcesGet(ctx, gvk, namespace.(string), name.(string))
+	ret, err := k.ResourcesGet(ctx, gvk, namespace.(string), name.(string))
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to get resource: %v", err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get resource: %w", err)), nil
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
 }
 
 func (s *Server) resourcesCreateOrUpdate(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	resource := ctr.Params.Arguments["resource"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	resource := ctr.GetArguments()["resource"]
 	if resource == nil || resource == "" {
-		return NewTextResult("", errors.New("failed to create or update resources, missing argument resource")), nil
+		return NewTextResult(ctx, "", errors.New("failed to create or update resources, missing argument resource")), nil
 	}
-	ret, err := s.k.ResourcesCreateOrUpdate(ctx, resource.(string))
+	ret, err := k.ResourcesCreateOrUpdate(ctx, resource.(string))
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to create or update resources: %v", err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to create or update resources: %w", err)), nil
 	}
-	return NewTextResult(ret, err), nil
+	return NewTextResult(ctx, ret, err), nil
 }
 
 func (s *Server) resourcesDelete(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace := ctr.Params.Arguments["namespace"]
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	namespace := ctr.GetArguments()["namespace"]
 	if namespace == nil {
 		namespace = ""
 	}
-	gvk, err := parseGroupVersionKind(ctr.Params.Arguments)
+	gvk, err := parseGroupVersionKind(ctr.GetArguments())
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to delete resource, %s", err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to delete resource, %s", err)), nil
 	}
-	name := ctr.Params.Arguments["name"]
+	name := ctr.GetArguments()["name"]
 	if name == nil {
-		return NewTextResult("", errors.New("failed to delete resource, missing argument name")), nil
+		return NewTextResult(ctx, "", errors.New("failed to delete resource, missing argument name")), nil
+	}
+	err = k.ResourcesDelete(ctx, gvk, namespace.(string), name.(string))
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to delete resource: %w", err)), nil
+	}
+	return NewTextResult(ctx, "Resource deleted successfully", err), nil
+}
+
+func (s *Server) resourcesScale(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	namespace := ctr.GetArguments()["namespace"]
+	if namespace == nil {
+		namespace = ""
+	}
+	gvk, err := parseGroupVersionKind(ctr.GetArguments())
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to scale resource, %s", err)), nil
+	}
+	name := ctr.GetArguments()["name"]
+	if name == nil {
+		return NewTextResult(ctx, "", errors.New("failed to scale resource, missing argument name")), nil
+	}
+	options := kubernetes.ResourcesScaleOptions{}
+	if options.Replicas, err = integerArgument(ctr.GetArguments(), "replicas"); err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to scale resource, %s", err)), nil
+	}
+	if wait, ok := ctr.GetArguments()["wait"].(bool); ok {
+		options.Wait = wait
+	}
+	if timeout, ok := ctr.GetArguments()["timeout"].(float64); ok {
+		options.Timeout = time.Duration(timeout * float64(time.Second))
 	}
-	err = s.k.ResourcesDelete(ctx, gvk, namespace.(string), name.(string))
+	ret, err := k.ResourcesScale(ctx, gvk, namespace.(string), name.(string), options)
 	if err != nil {
-		return NewTextResult("", fmt.Errorf("failed to delete resource: %v", err)), nil
+		return NewTextResult(ctx, "", fmt.Errorf("failed to scale resource: %w", err)), nil
 	}
-	return NewTextResult("Resource deleted successfully", err), nil
+	return NewTextResult(ctx, ret, err), nil
 }
 
 func parseGroupVersionKind(arguments map[string]interface{}) (*schema.GroupVersionKind, error) {
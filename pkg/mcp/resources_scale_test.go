@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"encoding/json"
+	"github.com/mark3labs/mcp-go/mcp"
+	"io"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"net/http"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func handleAppsDiscovery(w http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case "/api":
+		writeObject(w, &metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}, Versions: []string{"v1"}})
+	case "/apis":
+		writeObject(w, &metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"}, Groups: []metav1.APIGroup{{
+			Name:             "apps",
+			Versions:         []metav1.GroupVersionForDiscovery{{GroupVersion: "apps/v1", Version: "v1"}},
+			PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "apps/v1", Version: "v1"},
+		}}})
+	case "/api/v1":
+		writeObject(w, &metav1.APIResourceList{TypeMeta: metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"}, GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get", "list"}},
+		}})
+	case "/apis/apps/v1":
+		writeObject(w, &metav1.APIResourceList{TypeMeta: metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"}, GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"get", "list", "patch"}},
+			{Name: "deployments/scale", Kind: "Scale", Group: "autoscaling", Version: "v1", Namespaced: true, Verbs: []string{"get", "patch", "update"}},
+		}})
+	}
+}
+
+func TestResourcesScale(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		mockServer.Handle(http.HandlerFunc(handleAppsDiscovery))
+		// The mock Deployment gets one more ready replica on every request until it matches the desired replicas
+		var mutex sync.Mutex
+		replicas, readyReplicas := int64(1), int64(1)
+		scale := func() *autoscalingv1.Scale {
+			return &autoscalingv1.Scale{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v1", Kind: "Scale"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+				Spec:       autoscalingv1.ScaleSpec{Replicas: int32(replicas)},
+				Status:     autoscalingv1.ScaleStatus{Replicas: int32(replicas)},
+			}
+		}
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			switch req.URL.Path {
+			case "/apis/apps/v1/namespaces/default/deployments/web":
+				if readyReplicas < replicas {
+					readyReplicas++
+				}
+				writeObject(w, &unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"metadata":   map[string]interface{}{"namespace": "default", "name": "web"},
+					"status":     map[string]interface{}{"replicas": replicas, "readyReplicas": readyReplicas},
+				}})
+			case "/apis/apps/v1/namespaces/default/deployments/web/scale":
+				if req.Method == http.MethodPatch {
+					body, _ := io.ReadAll(req.Body)
+					patch := &autoscalingv1.Scale{}
+					_ = json.Unmarshal(body, patch)
+					replicas = int64(patch.Spec.Replicas)
+				}
+				writeObject(w, scale())
+			}
+		}))
+		t.Run("resources_scale without replicas returns current scale", func(t *testing.T) {
+			toolResult, err := c.callTool("resources_scale", map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "namespace": "default", "name": "web"})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			var result map[string]interface{}
+			_ = yaml.Unmarshal([]byte(toolResult.Content[0].(mcp.TextContent).Text), &result)
+			if result["replicas"] != float64(1) || result["currentReplicas"] != float64(1) || result["readyReplicas"] != float64(1) {
+				t.Fatalf("unexpected result %v", result)
+			}
+			if _, ok := result["previousReplicas"]; ok {
+				t.Fatalf("unexpected previous replicas %v", result)
+			}
+		})
+		t.Run("resources_scale with replicas and wait scales and waits for ready replicas", func(t *testing.T) {
+			toolResult, err := c.callTool("resources_scale", map[string]interface{}{
+				"apiVersion": "apps/v1", "kind": "Deployment", "namespace": "default", "name": "web", "replicas": 3, "wait": true,
+			})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			var result map[string]interface{}
+			_ = yaml.Unmarshal([]byte(toolResult.Content[0].(mcp.TextContent).Text), &result)
+			if result["kind"] != "Deployment" || result["name"] != "web" || result["previousReplicas"] != float64(1) ||
+				result["replicas"] != float64(3) || result["currentReplicas"] != float64(3) || result["readyReplicas"] != float64(3) {
+				t.Fatalf("unexpected result %v", result)
+			}
+		})
+		t.Run("resources_scale with wait timeout returns error", func(t *testing.T) {
+			mutex.Lock()
+			readyReplicas = -100
+			mutex.Unlock()
+			toolResult, _ := c.callTool("resources_scale", map[string]interface{}{
+				"apiVersion": "apps/v1", "kind": "Deployment", "namespace": "default", "name": "web", "wait": true, "timeout": 1,
+			})
+			if !toolResult.IsError || !strings.HasPrefix(toolResult.Content[0].(mcp.TextContent).Text,
+				"failed to scale resource: timed out after 1s waiting for 3 ready replicas (current replicas: 3, ready replicas: -") {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+		t.Run("resources_scale with negative replicas returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_scale", map[string]interface{}{
+				"apiVersion": "apps/v1", "kind": "Deployment", "namespace": "default", "name": "web", "replicas": -1,
+			})
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != "failed to scale resource: replicas must not be negative" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+		t.Run("resources_scale with fractional replicas returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_scale", map[string]interface{}{
+				"apiVersion": "apps/v1", "kind": "Deployment", "namespace": "default", "name": "web", "replicas": 2.5,
+			})
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != "failed to scale resource, invalid replicas argument 2.5, must be an integer" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+		t.Run("resources_scale with resource without scale subresource returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_scale", map[string]interface{}{
+				"apiVersion": "v1", "kind": "ConfigMap", "namespace": "default", "name": "config", "replicas": 2,
+			})
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != "failed to scale resource: v1 ConfigMap does not support scaling (no scale subresource)" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+	})
+}
@@ -1,13 +1,19 @@
 package mcp
 
 import (
+	"encoding/json"
+	"github.com/manusa/kubernetes-mcp-server/pkg/audit"
 	"github.com/mark3labs/mcp-go/mcp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
+	"net/http"
+	"os"
+	"path/filepath"
 	"sigs.k8s.io/yaml"
 	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -454,3 +460,247 @@ func TestResourcesDelete(t *testing.T) {
 		})
 	})
 }
+
+func TestResourcesDenied(t *testing.T) {
+	deniedResources := []string{"v1 Secret", "rbac.authorization.k8s.io/* *"}
+	testCaseWithContext(t, &mcpContext{deniedResources: deniedResources}, func(c *mcpContext) {
+		c.withEnvTest()
+		t.Run("resources_list with denied kind returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_list", map[string]interface{}{"apiVersion": "v1", "kind": "Secret"})
+			if !toolResult.IsError {
+				t.Fatalf("call tool should fail")
+			}
+			if toolResult.Content[0].(mcp.TextContent).Text != `failed to list resources: resource not allowed: v1 Secret is denied by the server configuration (rule "v1 Secret")` {
+				t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("resources_list with denied group wildcard returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_list", map[string]interface{}{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRole"})
+			if !toolResult.IsError {
+				t.Fatalf("call tool should fail")
+			}
+			if toolResult.Content[0].(mcp.TextContent).Text != `failed to list resources: resource not allowed: rbac.authorization.k8s.io/v1 ClusterRole is denied by the server configuration (rule "rbac.authorization.k8s.io/* *")` {
+				t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("resources_get with denied kind returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_get", map[string]interface{}{"apiVersion": "v1", "kind": "Secret", "namespace": "default", "name": "a-secret"})
+			if !toolResult.IsError {
+				t.Fatalf("call tool should fail")
+			}
+			if !strings.Contains(toolResult.Content[0].(mcp.TextContent).Text, "resource not allowed: v1 Secret") {
+				t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("resources_create_or_update with denied kind returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_create_or_update", map[string]interface{}{
+				"resource": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: a-denied-secret\n  namespace: default\n",
+			})
+			if !toolResult.IsError {
+				t.Fatalf("call tool should fail")
+			}
+			if !strings.Contains(toolResult.Content[0].(mcp.TextContent).Text, "resource not allowed: v1 Secret") {
+				t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("resources_delete with denied kind returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_delete", map[string]interface{}{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRole", "name": "allow-all"})
+			if !toolResult.IsError {
+				t.Fatalf("call tool should fail")
+			}
+			if !strings.Contains(toolResult.Content[0].(mcp.TextContent).Text, "resource not allowed: rbac.authorization.k8s.io/v1 ClusterRole") {
+				t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		t.Run("resources_list with allowed kind returns resources", func(t *testing.T) {
+			toolResult, _ := c.callTool("resources_list", map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"})
+			if toolResult.IsError {
+				t.Fatalf("call tool failed %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+	})
+}
+
+func TestPodsDenied(t *testing.T) {
+	testCaseWithContext(t, &mcpContext{deniedResources: []string{"* Pod"}}, func(c *mcpContext) {
+		c.withEnvTest()
+		for _, tool := range []string{"pods_list", "pods_get", "pods_delete", "pods_log", "pods_exec"} {
+			t.Run(tool+" with denied Pod kind returns error", func(t *testing.T) {
+				toolResult, _ := c.callTool(tool, map[string]interface{}{
+					"namespace": "default",
+					"name":      "a-pod-in-default",
+					"command":   []interface{}{"ls"},
+				})
+				if !toolResult.IsError {
+					t.Fatalf("call tool should fail")
+				}
+				if !strings.Contains(toolResult.Content[0].(mcp.TextContent).Text, "resource not allowed: v1 Pod is denied by the server configuration") {
+					t.Fatalf("invalid error message, got %v", toolResult.Content[0].(mcp.TextContent).Text)
+				}
+			})
+		}
+	})
+}
+
+func TestResourcesDeniedInvalidConfiguration(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		_, err := NewSever(Configuration{DeniedResources: []string{"Secret"}})
+		t.Run("NewServer with invalid denied resources returns error", func(t *testing.T) {
+			if err == nil {
+				t.Fatalf("expected error for invalid denied resources")
+			}
+			if !strings.HasPrefix(err.Error(), `invalid denied resources configuration: invalid denied resource "Secret"`) {
+				t.Fatalf("invalid error message, got %v", err)
+			}
+		})
+	})
+}
+
+func TestResourcesAuditLog(t *testing.T) {
+	auditLogPath := filepath.Join(t.TempDir(), "audit.log")
+	closeAuditLog, err := audit.Init(audit.Options{Path: auditLogPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closeAuditLog() }()
+	testCase(t, func(c *mcpContext) {
+		c.withEnvTest()
+		configMapYaml := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a-cm-audited\n  namespace: default\n"
+		_, _ = c.callTool("resources_create_or_update", map[string]interface{}{"resource": configMapYaml})
+		_, _ = c.callTool("resources_delete", map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap", "namespace": "default", "name": "a-cm-audited",
+		})
+		auditLog, err := os.ReadFile(auditLogPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var records []*audit.Record
+		for _, line := range strings.Split(strings.TrimSpace(string(auditLog)), "\n") {
+			record := &audit.Record{}
+			if err = json.Unmarshal([]byte(line), record); err != nil {
+				t.Fatalf("invalid audit log record %s: %v", line, err)
+			}
+			records = append(records, record)
+		}
+		t.Run("resources_create_or_update records the applied object identity", func(t *testing.T) {
+			if len(records) != 2 || len(records[0].Objects) != 1 {
+				t.Fatalf("expected applied object in first record, got %v", records)
+			}
+			object := records[0].Objects[0]
+			if object.Action != audit.ActionApply || object.Kind != "ConfigMap" || object.Name != "a-cm-audited" ||
+				object.UID == "" || object.ResourceVersion == "" {
+				t.Fatalf("invalid applied object, got %v", object)
+			}
+		})
+		t.Run("resources_delete records the deleted object identity", func(t *testing.T) {
+			if len(records) != 2 || len(records[1].Objects) != 1 {
+				t.Fatalf("expected deleted object in second record, got %v", records)
+			}
+			object := records[1].Objects[0]
+			if object.Action != audit.ActionDelete || object.UID != records[0].Objects[0].UID {
+				t.Fatalf("invalid deleted object, got %v", object)
+			}
+		})
+	})
+}
+
+func TestResourcesDeleteAuditLogWithoutGetPermission(t *testing.T) {
+	auditLogPath := filepath.Join(t.TempDir(), "audit.log")
+	closeAuditLog, err := audit.Init(audit.Options{Path: auditLogPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closeAuditLog() }()
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		mockServer.Handle(http.HandlerFunc(handleAppsDiscovery))
+		deleted := false
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/api/v1/namespaces/default/configmaps/a-cm" {
+				return
+			}
+			switch req.Method {
+			case http.MethodGet:
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				_ = json.NewEncoder(w).Encode(&metav1.Status{TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+					Status: metav1.StatusFailure, Reason: metav1.StatusReasonForbidden, Code: http.StatusForbidden})
+			case http.MethodDelete:
+				deleted = true
+				writeObject(w, &metav1.Status{TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}, Status: metav1.StatusSuccess})
+			}
+		}))
+		toolResult, err := c.callTool("resources_delete", map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap", "namespace": "default", "name": "a-cm",
+		})
+		t.Run("resources_delete without get permission deletes the resource", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			if !deleted {
+				t.Fatalf("expected resource to be deleted")
+			}
+		})
+		t.Run("resources_delete without get permission records the deleted object name", func(t *testing.T) {
+			auditLog, err := os.ReadFile(auditLogPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			record := &audit.Record{}
+			if err = json.Unmarshal(auditLog, record); err != nil {
+				t.Fatalf("invalid audit log record %s: %v", auditLog, err)
+			}
+			if record.Outcome != audit.OutcomeSuccess || len(record.Objects) != 1 {
+				t.Fatalf("expected successful record with deleted object, got %v", record)
+			}
+			object := record.Objects[0]
+			if object.Action != audit.ActionDelete || object.Kind != "ConfigMap" || object.Namespace != "default" || object.Name != "a-cm" || object.UID != "" {
+				t.Fatalf("invalid deleted object, got %v", object)
+			}
+		})
+	})
+}
+
+func TestResourcesGetWithFailedDiscovery(t *testing.T) {
+	testCaseWithContext(t, &mcpContext{allowedNamespaces: []string{"ns-1"}}, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		var failDiscovery, deniedNamespaceRequested atomic.Bool
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch {
+			case req.URL.Path == "/api/v1" && failDiscovery.Load():
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case req.URL.Path == "/api/v1/namespaces/ns-1/configmaps/a-cm":
+				writeObject(w, &unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"namespace": "ns-1", "name": "a-cm"},
+				}})
+			case strings.HasPrefix(req.URL.Path, "/api/v1/namespaces/default/"):
+				deniedNamespaceRequested.Store(true)
+			default:
+				handleAppsDiscovery(w, req)
+			}
+		}))
+		// The first call fills the cached discovery of the REST mapper
+		toolResult, err := c.callTool("resources_get", map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "namespace": "ns-1", "name": "a-cm"})
+		t.Run("resources_get in allowed namespace returns resource", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+		})
+		failDiscovery.Store(true)
+		toolResult, _ = c.callTool("resources_get", map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "namespace": "default", "name": "a-cm"})
+		t.Run("resources_get with failed discovery returns error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.Contains(toolResult.Content[0].(mcp.TextContent).Text, "failed to resolve the scope of v1 ConfigMap") {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+		t.Run("resources_get with failed discovery doesn't request the denied namespace", func(t *testing.T) {
+			if deniedNamespaceRequested.Load() {
+				t.Fatalf("expected no request to the denied namespace")
+			}
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// payloadArguments are the arguments carrying arbitrary data (pods_cp_to content and pods_exec stdin),
+// only their size and hash are recorded
+var payloadArguments = []string{"content", "stdin"}
+
+var sensitiveKey = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|private.?key|api.?key|authorization)`)
+
+// sanitizeArguments returns a copy of the tool call arguments with the sensitive values redacted:
+// values of keys that look like credentials and the data of any Secret manifest (e.g. resources_create_or_update).
+// The payload arguments are replaced with their size and hash.
+func sanitizeArguments(arguments map[string]any) map[string]any {
+	sanitized := sanitize(arguments).(map[string]any)
+	for _, key := range payloadArguments {
+		if payload, ok := arguments[key].(string); ok {
+			sanitized[key] = fmt.Sprintf("[%d bytes, sha256:%x]", len(payload), sha256.Sum256([]byte(payload)))
+		}
+	}
+	return sanitized
+}
+
+func sanitize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		sanitized := make(map[string]any, len(v))
+		for key, item := range v {
+			if sensitiveKey.MatchString(key) {
+				sanitized[key] = redacted
+			} else {
+				sanitized[key] = sanitize(item)
+			}
+		}
+		if kind, _ := v["kind"].(string); kind == "Secret" {
+			for _, field := range []string{"data", "stringData"} {
+				if data, ok := sanitized[field].(map[string]any); ok {
+					for key := range data {
+						data[key] = redacted
+					}
+				}
+			}
+		}
+		return sanitized
+	case []any:
+		sanitized := make([]any, len(v))
+		for i, item := range v {
+			sanitized[i] = sanitize(item)
+		}
+		return sanitized
+	case string:
+		return sanitizeManifest(v)
+	default:
+		return v
+	}
+}
+
+// sanitizeManifest redacts the sensitive values of a YAML or JSON manifest provided as a string argument
+func sanitizeManifest(value string) string {
+	if !strings.Contains(value, "Secret") && !sensitiveKey.MatchString(value) {
+		return value
+	}
+	var manifest map[string]any
+	if err := yaml.Unmarshal([]byte(value), &manifest); err != nil || manifest["kind"] == nil {
+		return value
+	}
+	sanitized, err := yaml.Marshal(sanitize(manifest))
+	if err != nil {
+		return redacted
+	}
+	return string(sanitized)
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeArguments(t *testing.T) {
+	arguments := map[string]any{
+		"namespace": "default",
+		"token":     "a-token",
+		"nested":    map[string]any{"password": "a-password", "name": "a-name"},
+		"resource":  "apiVersion: v1\nkind: Secret\nmetadata:\n  name: a-secret\nstringData:\n  user: admin\ndata:\n  key: c2VjcmV0\n",
+	}
+	sanitized := sanitizeArguments(arguments)
+	t.Run("sanitizeArguments keeps regular values", func(t *testing.T) {
+		if sanitized["namespace"] != "default" {
+			t.Fatalf("expected namespace to be kept, got %v", sanitized["namespace"])
+		}
+		if sanitized["nested"].(map[string]any)["name"] != "a-name" {
+			t.Fatalf("expected nested name to be kept, got %v", sanitized["nested"])
+		}
+	})
+	t.Run("sanitizeArguments redacts sensitive keys", func(t *testing.T) {
+		if sanitized["token"] != redacted {
+			t.Fatalf("expected token to be redacted, got %v", sanitized["token"])
+		}
+		if sanitized["nested"].(map[string]any)["password"] != redacted {
+			t.Fatalf("expected nested password to be redacted, got %v", sanitized["nested"])
+		}
+	})
+	t.Run("sanitizeArguments redacts Secret manifest data", func(t *testing.T) {
+		resource := sanitized["resource"].(string)
+		if strings.Contains(resource, "admin") || strings.Contains(resource, "c2VjcmV0") {
+			t.Fatalf("expected Secret data to be redacted, got %s", resource)
+		}
+		if !strings.Contains(resource, "name: a-secret") {
+			t.Fatalf("expected Secret metadata to be kept, got %s", resource)
+		}
+	})
+	t.Run("sanitizeArguments doesn't modify the provided arguments", func(t *testing.T) {
+		if arguments["token"] != "a-token" || arguments["nested"].(map[string]any)["password"] != "a-password" {
+			t.Fatalf("expected arguments to be unmodified, got %v", arguments)
+		}
+	})
+}
+
+func TestSanitizeArgumentsPayload(t *testing.T) {
+	arguments := map[string]any{
+		"name":    "a-pod",
+		"content": strings.Repeat("a large file content\n", 10000),
+		"stdin":   "hello",
+	}
+	sanitized := sanitizeArguments(arguments)
+	t.Run("sanitizeArguments replaces content with its size and hash", func(t *testing.T) {
+		if !strings.HasPrefix(sanitized["content"].(string), "[210000 bytes, sha256:") || len(sanitized["content"].(string)) > 100 {
+			t.Fatalf("expected content size and hash, got %v", sanitized["content"])
+		}
+	})
+	t.Run("sanitizeArguments replaces stdin with its size and hash", func(t *testing.T) {
+		if sanitized["stdin"] != "[5 bytes, sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824]" {
+			t.Fatalf("expected stdin size and hash, got %v", sanitized["stdin"])
+		}
+	})
+	t.Run("sanitizeArguments keeps other arguments", func(t *testing.T) {
+		if sanitized["name"] != "a-pod" {
+			t.Fatalf("expected name to be kept, got %v", sanitized["name"])
+		}
+	})
+}
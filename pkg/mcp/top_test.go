@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+	authv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"net/http"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func podMetrics(namespace, name string, containers ...map[string]interface{}) map[string]interface{} {
+	items := make([]interface{}, 0, len(containers))
+	for _, container := range containers {
+		items = append(items, container)
+	}
+	return map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata":   map[string]interface{}{"namespace": namespace, "name": name},
+		"containers": items,
+	}
+}
+
+func containerMetrics(name, cpu, memory string) map[string]interface{} {
+	return map[string]interface{}{"name": name, "usage": map[string]interface{}{"cpu": cpu, "memory": memory}}
+}
+
+func handleMetricsDiscovery(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/apis/metrics.k8s.io/v1beta1" {
+		writeObject(w, &metav1.APIResourceList{
+			TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+			GroupVersion: "metrics.k8s.io/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "PodMetrics", Namespaced: true, Verbs: []string{"get", "list"}},
+				{Name: "nodes", Kind: "NodeMetrics", Namespaced: false, Verbs: []string{"get", "list"}},
+			},
+		})
+	}
+}
+
+func TestPodsTop(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		mockServer.Handle(http.HandlerFunc(handleMetricsDiscovery))
+		var clusterWideAllowed atomic.Bool
+		clusterWideAllowed.Store(true)
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews":
+				// pods_top only reviews the cluster-wide list permissions
+				writeObject(w, &authv1.SelfSubjectAccessReview{
+					TypeMeta: metav1.TypeMeta{APIVersion: "authorization.k8s.io/v1", Kind: "SelfSubjectAccessReview"},
+					Status:   authv1.SubjectAccessReviewStatus{Allowed: clusterWideAllowed.Load()},
+				})
+			case "/apis/metrics.k8s.io/v1beta1/pods", "/apis/metrics.k8s.io/v1beta1/namespaces/default/pods":
+				items := []unstructured.Unstructured{
+					{Object: podMetrics("default", "web", containerMetrics("web", "250m", "64Mi"), containerMetrics("sidecar", "50m", "32Mi"))},
+					{Object: podMetrics("default", "db", containerMetrics("db", "100m", "512Mi"))},
+				}
+				if req.URL.Path == "/apis/metrics.k8s.io/v1beta1/pods" {
+					items = append(items, unstructured.Unstructured{Object: podMetrics("kube-system", "dns", containerMetrics("dns", "5m", "16Mi"))})
+				}
+				writeObject(w, &unstructured.UnstructuredList{
+					Object: map[string]interface{}{"apiVersion": "metrics.k8s.io/v1beta1", "kind": "PodMetricsList"},
+					Items:  items,
+				})
+			case "/api/v1/pods", "/api/v1/namespaces/default/pods":
+				writeObject(w, &v1.PodList{Items: []v1.Pod{
+					{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+						Spec: v1.PodSpec{Containers: []v1.Container{
+							{Name: "web", Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m"), v1.ResourceMemory: resource.MustParse("64Mi")},
+								Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("128Mi")},
+							}},
+							{Name: "sidecar", Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("50m")},
+								Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("64Mi")},
+							}},
+						}},
+					},
+				}})
+			}
+		}))
+		toolResult, err := c.callTool("pods_top", map[string]interface{}{"namespace": "default", "sortBy": "memory"})
+		t.Run("pods_top returns pod resource usage", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			text := toolResult.Content[0].(mcp.TextContent).Text
+			if !strings.HasPrefix(text, "The following pod resource usage (YAML format) was found:\n") {
+				t.Fatalf("unexpected result %v", text)
+			}
+			var result []map[string]interface{}
+			if err = yaml.Unmarshal([]byte(text[strings.Index(text, "\n")+1:]), &result); err != nil {
+				t.Fatalf("invalid tool result content %v", err)
+			}
+			if len(result) != 2 {
+				t.Fatalf("unexpected number of pods %d", len(result))
+			}
+			t.Run("sorted by memory", func(t *testing.T) {
+				if result[0]["name"] != "db" || result[1]["name"] != "web" {
+					t.Fatalf("unexpected order %v, %v", result[0]["name"], result[1]["name"])
+				}
+			})
+			t.Run("sums container usage", func(t *testing.T) {
+				if result[1]["cpu"] != "300m" || result[1]["memory"] != "96Mi" {
+					t.Fatalf("unexpected usage %v", result[1])
+				}
+			})
+			t.Run("joins requests and limits", func(t *testing.T) {
+				if result[1]["cpuRequest"] != "250m" || result[1]["memoryRequest"] != "64Mi" || result[1]["memoryLimit"] != "192Mi" {
+					t.Fatalf("unexpected requests and limits %v", result[1])
+				}
+				if _, ok := result[1]["cpuLimit"]; ok {
+					t.Fatalf("unexpected cpu limit for pod with an unlimited container %v", result[1])
+				}
+				containers := result[1]["containers"].([]interface{})
+				web := containers[0].(map[string]interface{})
+				if web["name"] != "web" || web["cpu"] != "250m" || web["cpuRequest"] != "200m" || web["cpuLimit"] != "1000m" || web["memoryLimit"] != "128Mi" {
+					t.Fatalf("unexpected container usage %v", web)
+				}
+			})
+			t.Run("omits requests and limits of pods not found", func(t *testing.T) {
+				if _, ok := result[0]["cpuRequest"]; ok {
+					t.Fatalf("unexpected requests %v", result[0])
+				}
+			})
+		})
+		t.Run("pods_top in all namespaces sorted by cpu", func(t *testing.T) {
+			allNamespaces, err := c.callTool("pods_top", map[string]interface{}{"sortBy": "cpu"})
+			if err != nil || allNamespaces.IsError {
+				t.Fatalf("call tool failed %v %v", err, allNamespaces.Content)
+			}
+			text := allNamespaces.Content[0].(mcp.TextContent).Text
+			var result []map[string]interface{}
+			_ = yaml.Unmarshal([]byte(text[strings.Index(text, "\n")+1:]), &result)
+			if len(result) != 3 || result[0]["name"] != "web" || result[1]["name"] != "db" || result[2]["name"] != "dns" {
+				t.Fatalf("unexpected result %v", result)
+			}
+		})
+		t.Run("pods_top in all namespaces without cluster-wide permission returns pods in the configured namespace", func(t *testing.T) {
+			clusterWideAllowed.Store(false)
+			defer clusterWideAllowed.Store(true)
+			configuredNamespace, err := c.callTool("pods_top", map[string]interface{}{"sortBy": "cpu"})
+			if err != nil || configuredNamespace.IsError {
+				t.Fatalf("call tool failed %v %v", err, configuredNamespace.Content)
+			}
+			text := configuredNamespace.Content[0].(mcp.TextContent).Text
+			var result []map[string]interface{}
+			_ = yaml.Unmarshal([]byte(text[strings.Index(text, "\n")+1:]), &result)
+			if len(result) != 2 || result[0]["namespace"] != "default" || result[1]["namespace"] != "default" {
+				t.Fatalf("unexpected result %v", result)
+			}
+		})
+		t.Run("pods_top with invalid sortBy returns error", func(t *testing.T) {
+			invalid, _ := c.callTool("pods_top", map[string]interface{}{"sortBy": "disk"})
+			if !invalid.IsError || invalid.Content[0].(mcp.TextContent).Text != "failed to get pods resource usage: invalid sort by disk, valid values are: cpu, memory" {
+				t.Fatalf("unexpected result %v", invalid.Content)
+			}
+		})
+	})
+}
+
+func TestNodesTop(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		mockServer.Handle(http.HandlerFunc(handleMetricsDiscovery))
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/apis/metrics.k8s.io/v1beta1/nodes":
+				nodeMetrics := func(name, cpu, memory string) unstructured.Unstructured {
+					return unstructured.Unstructured{Object: map[string]interface{}{
+						"apiVersion": "metrics.k8s.io/v1beta1",
+						"kind":       "NodeMetrics",
+						"metadata":   map[string]interface{}{"name": name},
+						"usage":      map[string]interface{}{"cpu": cpu, "memory": memory},
+					}}
+				}
+				writeObject(w, &unstructured.UnstructuredList{
+					Object: map[string]interface{}{"apiVersion": "metrics.k8s.io/v1beta1", "kind": "NodeMetricsList"},
+					Items:  []unstructured.Unstructured{nodeMetrics("node-a", "500m", "1Gi"), nodeMetrics("node-b", "1500m", "512Mi")},
+				})
+			case "/api/v1/nodes":
+				writeObject(w, &v1.NodeList{Items: []v1.Node{
+					{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}, Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+						v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("4Gi"),
+					}}},
+				}})
+			}
+		}))
+		toolResult, err := c.callTool("nodes_top", map[string]interface{}{"sortBy": "cpu"})
+		t.Run("nodes_top returns node resource usage", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			text := toolResult.Content[0].(mcp.TextContent).Text
+			if !strings.HasPrefix(text, "The following node resource usage (YAML format) was found:\n") {
+				t.Fatalf("unexpected result %v", text)
+			}
+			var result []map[string]interface{}
+			_ = yaml.Unmarshal([]byte(text[strings.Index(text, "\n")+1:]), &result)
+			if len(result) != 2 || result[0]["name"] != "node-b" || result[1]["name"] != "node-a" {
+				t.Fatalf("unexpected result %v", result)
+			}
+			if result[1]["cpu"] != "500m" || result[1]["cpuAllocatable"] != "2000m" || result[1]["cpuPercent"] != float64(25) ||
+				result[1]["memory"] != "1024Mi" || result[1]["memoryAllocatable"] != "4096Mi" || result[1]["memoryPercent"] != float64(25) {
+				t.Fatalf("unexpected usage %v", result[1])
+			}
+		})
+	})
+}
+
+func TestTopWithoutMetricsServer(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/apis/metrics.k8s.io/v1beta1" {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		for _, tool := range []string{"pods_top", "nodes_top"} {
+			toolResult, _ := c.callTool(tool, map[string]interface{}{})
+			t.Run(tool+" without metrics API returns error", func(t *testing.T) {
+				if !toolResult.IsError || !strings.HasSuffix(toolResult.Content[0].(mcp.TextContent).Text,
+					"metrics API (metrics.k8s.io/v1beta1) is not available, make sure metrics-server is installed and running in the cluster") {
+					t.Fatalf("unexpected result %v", toolResult.Content)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"strings"
+	"time"
+)
+
+func (s *Server) initWorkloads() []server.ServerTool {
+	return []server.ServerTool{
+		{Tool: mcp.NewTool("workload_logs",
+			mcp.WithDescription("Get the logs of all the containers of the Pods of a Kubernetes workload (or matching a label selector) in the current or provided namespace, "+
+				"interleaved by timestamp and prefixed with [pod/container]"),
+			mcp.WithString("namespace", mcp.Description("Namespace of the workload")),
+			mcp.WithString("kind", mcp.Description("Kind of the workload, one of: "+strings.Join(kubernetes.WorkloadKinds, ", ")+" (Optional, required if labelSelector is not provided)"),
+				mcp.Enum(kubernetes.WorkloadKinds...)),
+			mcp.WithString("name", mcp.Description("Name of the workload (Optional, required if labelSelector is not provided)")),
+			mcp.WithString("labelSelector", mcp.Description("Label selector of the Pods to get the logs from, e.g. app=nginx (Optional, narrows the Pods of the workload if kind and name are provided)")),
+			mcp.WithBoolean("previous", mcp.Description("Return the logs of the previous terminated container instances (Optional, defaults to false)")),
+			mcp.WithNumber("tail", mcp.Description(fmt.Sprintf("Number of lines from the end of the logs to return for each container (Optional, defaults to %d unless sinceSeconds or sinceTime are provided)", kubernetes.PodsLogDefaultTailLines))),
+			mcp.WithNumber("sinceSeconds", mcp.Description("Return only the logs newer than the provided number of seconds (Optional)")),
+			mcp.WithString("sinceTime", mcp.Description("Return only the logs newer than the provided RFC3339 timestamp, e.g. 2025-01-01T10:00:00Z (Optional)")),
+			mcp.WithBoolean("timestamps", mcp.Description("Include the RFC3339 timestamp of each log line (Optional, defaults to false)")),
+			mcp.WithString("filter", mcp.Description("Regular expression to return only the matching log lines, e.g. (?i)error|warn (Optional)")),
+			withContext(),
+			mcp.WithTitleAnnotation("Workloads: Logs"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.workloadLogs},
+		{Tool: mcp.NewTool("rollout_status",
+			mcp.WithDescription("Get the rollout status of a Kubernetes workload in the current or provided namespace (as kubectl rollout status), "+
+				"optionally waiting until the rollout completes. Returns whether the rollout is done, the progress message, and the workload status conditions"),
+			withRolloutTarget(),
+			mcp.WithBoolean("wait", mcp.Description("Wait until the rollout completes (Optional, defaults to false)")),
+			mcp.WithNumber("timeout", mcp.Description(fmt.Sprintf("Maximum number of seconds to wait for the rollout to complete (Optional, defaults to %d, maximum %d)",
+				int(kubernetes.RolloutStatusDefaultTimeout.Seconds()), int(kubernetes.RolloutStatusMaxTimeout.Seconds())))),
+			withContext(),
+			mcp.WithTitleAnnotation("Rollout: Status"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.rolloutStatus},
+		{Tool: mcp.NewTool("rollout_history",
+			mcp.WithDescription("Get the rollout history of a Kubernetes workload in the current or provided namespace (as kubectl rollout history). "+
+				"Returns the revisions with their change cause and container images (ReplicaSets for Deployments, ControllerRevisions for StatefulSets and DaemonSets)"),
+			withRolloutTarget(),
+			withContext(),
+			mcp.WithTitleAnnotation("Rollout: History"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		), Handler: s.rolloutHistory},
+		{Tool: mcp.NewTool("rollout_restart",
+			mcp.WithDescription("Restart a Kubernetes workload in the current or provided namespace by triggering a new rollout of its Pods (as kubectl rollout restart)"),
+			withRolloutTarget(),
+			withContext(),
+			mcp.WithTitleAnnotation("Rollout: Restart"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+		), Handler: s.rolloutRestart},
+		{Tool: mcp.NewTool("rollout_undo",
+			mcp.WithDescription("Roll back a Kubernetes workload in the current or provided namespace to a previous revision of its rollout history (as kubectl rollout undo)"),
+			withRolloutTarget(),
+			mcp.WithNumber("toRevision", mcp.Description("Revision to roll back to, as listed by rollout_history (Optional, defaults to the previous revision)")),
+			withContext(),
+			mcp.WithTitleAnnotation("Rollout: Undo"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+		), Handler: s.rolloutUndo},
+	}
+}
+
+// withRolloutTarget adds the namespace, kind, and name arguments of the rollout tools
+func withRolloutTarget() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithString("namespace", mcp.Description("Namespace of the workload"))(tool)
+		mcp.WithString("kind", mcp.Description("Kind of the workload, one of: "+strings.Join(kubernetes.RolloutKinds, ", ")),
+			mcp.Enum(kubernetes.RolloutKinds...), mcp.Required())(tool)
+		mcp.WithString("name", mcp.Description("Name of the workload"), mcp.Required())(tool)
+	}
+}
+
+func (s *Server) workloadLogs(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns, _ := ctr.GetArguments()["namespace"].(string)
+	kind, _ := ctr.GetArguments()["kind"].(string)
+	name, _ := ctr.GetArguments()["name"].(string)
+	labelSelector, _ := ctr.GetArguments()["labelSelector"].(string)
+	workload := labelSelector
+	if kind != "" || name != "" {
+		workload = kind + " " + name
+		if labelSelector != "" {
+			workload += " (" + labelSelector + ")"
+		}
+	}
+	options, err := podsLogOptions(ctr.GetArguments())
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get workload %s logs in namespace %s: %w", workload, ns, err)), nil
+	}
+	options.Container = ""
+	ret, err := k.WorkloadLogs(ctx, ns, kind, name, labelSelector, options)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get workload %s logs in namespace %s: %w", workload, ns, err)), nil
+	} else if ret == "" {
+		ret = fmt.Sprintf("The pods of workload %s in namespace %s have not logged any message yet", workload, ns)
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
+
+func (s *Server) rolloutStatus(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns, _ := ctr.GetArguments()["namespace"].(string)
+	kind, _ := ctr.GetArguments()["kind"].(string)
+	name, _ := ctr.GetArguments()["name"].(string)
+	options := kubernetes.RolloutStatusOptions{}
+	if wait, ok := ctr.GetArguments()["wait"].(bool); ok {
+		options.Wait = wait
+	}
+	if timeout, ok := ctr.GetArguments()["timeout"].(float64); ok {
+		options.Timeout = time.Duration(timeout * float64(time.Second))
+	}
+	ret, err := k.RolloutStatus(ctx, ns, kind, name, options)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get rollout status of %s %s: %w", kind, name, err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
+
+func (s *Server) rolloutHistory(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns, _ := ctr.GetArguments()["namespace"].(string)
+	kind, _ := ctr.GetArguments()["kind"].(string)
+	name, _ := ctr.GetArguments()["name"].(string)
+	ret, err := k.RolloutHistory(ctx, ns, kind, name)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to get rollout history of %s %s: %w", kind, name, err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
+
+func (s *Server) rolloutRestart(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns, _ := ctr.GetArguments()["namespace"].(string)
+	kind, _ := ctr.GetArguments()["kind"].(string)
+	name, _ := ctr.GetArguments()["name"].(string)
+	ret, err := k.RolloutRestart(ctx, ns, kind, name)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to restart %s %s: %w", kind, name, err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
+
+func (s *Server) rolloutUndo(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	k, err := s.kubernetesFor(ctx, ctr)
+	if err != nil {
+		return NewTextResult(ctx, "", err), nil
+	}
+	ns, _ := ctr.GetArguments()["namespace"].(string)
+	kind, _ := ctr.GetArguments()["kind"].(string)
+	name, _ := ctr.GetArguments()["name"].(string)
+	toRevision, err := integerArgument(ctr.GetArguments(), "toRevision")
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to roll back %s %s: %w", kind, name, err)), nil
+	}
+	if toRevision == nil {
+		toRevision = new(int64)
+	}
+	ret, err := k.RolloutUndo(ctx, ns, kind, name, *toRevision)
+	if err != nil {
+		return NewTextResult(ctx, "", fmt.Errorf("failed to roll back %s %s: %w", kind, name, err)), nil
+	}
+	return NewTextResult(ctx, ret, err), nil
+}
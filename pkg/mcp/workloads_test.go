@@ -0,0 +1,430 @@
+package mcp
+
+import (
+	"encoding/json"
+	"github.com/mark3labs/mcp-go/mcp"
+	"io"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"net/http"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkloadLogs(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		var podsLabelSelector string
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/apis/apps/v1/namespaces/default/deployments/a-deployment" {
+				return
+			}
+			writeObject(w, &appsv1.Deployment{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a-deployment"},
+				Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "a-deployment"}}},
+			})
+		}))
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/api/v1/namespaces/default/pods" {
+				return
+			}
+			podsLabelSelector = req.URL.Query().Get("labelSelector")
+			writeObject(w, &v1.PodList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PodList"},
+				Items: []v1.Pod{
+					{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"},
+						Spec: v1.PodSpec{Containers: []v1.Container{{Name: "app"}, {Name: "sidecar"}}}},
+					{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-2"},
+						Spec: v1.PodSpec{Containers: []v1.Container{{Name: "app"}}}},
+				},
+			})
+		}))
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if !strings.HasPrefix(req.URL.Path, "/api/v1/namespaces/default/pods/") || !strings.HasSuffix(req.URL.Path, "/log") {
+				return
+			}
+			switch strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/api/v1/namespaces/default/pods/"), "/log") + "/" + req.URL.Query().Get("container") {
+			case "pod-1/app":
+				_, _ = w.Write([]byte("2025-01-01T10:00:01Z pod-1 app first\n2025-01-01T10:00:04Z pod-1 app ERROR second\n"))
+			case "pod-1/sidecar":
+				_, _ = w.Write([]byte("2025-01-01T10:00:02Z pod-1 sidecar first\n"))
+			case "pod-2/app":
+				_, _ = w.Write([]byte("2025-01-01T10:00:03Z pod-2 app ERROR first\n"))
+			}
+		}))
+		toolResult, err := c.callTool("workload_logs", map[string]interface{}{
+			"namespace": "default",
+			"kind":      "Deployment",
+			"name":      "a-deployment",
+		})
+		t.Run("workload_logs with Deployment returns logs", func(t *testing.T) {
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+		})
+		t.Run("workload_logs with Deployment lists Pods with the Deployment selector", func(t *testing.T) {
+			if podsLabelSelector != "app=a-deployment" {
+				t.Fatalf("unexpected label selector %s", podsLabelSelector)
+			}
+		})
+		t.Run("workload_logs with Deployment returns the logs of all containers interleaved by timestamp", func(t *testing.T) {
+			expected := "[pod-1/app] pod-1 app first\n" +
+				"[pod-1/sidecar] pod-1 sidecar first\n" +
+				"[pod-2/app] pod-2 app ERROR first\n" +
+				"[pod-1/app] pod-1 app ERROR second\n"
+			if toolResult.Content[0].(mcp.TextContent).Text != expected {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("workload_logs", map[string]interface{}{
+			"namespace":     "default",
+			"labelSelector": "app=a-deployment",
+			"timestamps":    true,
+			"filter":        "^ERROR|ERROR",
+		})
+		t.Run("workload_logs with label selector, timestamps and filter returns matching lines", func(t *testing.T) {
+			expected := "[pod-2/app] 2025-01-01T10:00:03Z pod-2 app ERROR first\n" +
+				"[pod-1/app] 2025-01-01T10:00:04Z pod-1 app ERROR second\n"
+			if toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != expected {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("workload_logs", map[string]interface{}{
+			"namespace":     "default",
+			"kind":          "Deployment",
+			"name":          "a-deployment",
+			"labelSelector": "tier=web",
+		})
+		t.Run("workload_logs with Deployment and label selector lists Pods matching both selectors", func(t *testing.T) {
+			if toolResult.IsError {
+				t.Fatalf("call tool failed %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+			if podsLabelSelector != "app=a-deployment,tier=web" {
+				t.Fatalf("unexpected label selector %s", podsLabelSelector)
+			}
+		})
+		toolResult, _ = c.callTool("workload_logs", map[string]interface{}{"namespace": "default", "kind": "ReplicaSet", "name": "a-replicaset"})
+		t.Run("workload_logs with unsupported kind returns error", func(t *testing.T) {
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text !=
+				"failed to get workload ReplicaSet a-replicaset logs in namespace default: unsupported workload kind ReplicaSet, supported kinds are: Deployment, StatefulSet, DaemonSet, Job" {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+		toolResult, _ = c.callTool("workload_logs", map[string]interface{}{"namespace": "default"})
+		t.Run("workload_logs without workload or label selector returns error", func(t *testing.T) {
+			if !toolResult.IsError || !strings.HasSuffix(toolResult.Content[0].(mcp.TextContent).Text,
+				"either a workload kind and name or a label selector must be provided") {
+				t.Fatalf("unexpected result %v", toolResult.Content[0].(mcp.TextContent).Text)
+			}
+		})
+	})
+}
+
+func TestRolloutStatus(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		// The mock Deployment gets one more updated and available replica on every request until it matches the desired replicas
+		var mutex sync.Mutex
+		updatedReplicas := int32(1)
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			switch req.URL.Path {
+			case "/apis/apps/v1/namespaces/default/deployments/web":
+				if updatedReplicas < 3 {
+					updatedReplicas++
+				}
+				writeObject(w, &appsv1.Deployment{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", Generation: 2},
+					Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(3))},
+					Status: appsv1.DeploymentStatus{
+						ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: updatedReplicas, AvailableReplicas: updatedReplicas,
+						Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: v1.ConditionTrue, Reason: "ReplicaSetUpdated"}},
+					},
+				})
+			case "/apis/apps/v1/namespaces/default/deployments/stuck":
+				writeObject(w, &appsv1.Deployment{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "stuck"},
+					Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+						{Type: appsv1.DeploymentProgressing, Status: v1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+					}},
+				})
+			case "/apis/apps/v1/namespaces/default/daemonsets/agent":
+				writeObject(w, &appsv1.DaemonSet{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "agent"},
+					Spec:       appsv1.DaemonSetSpec{UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}},
+				})
+			}
+		}))
+		t.Run("rollout_status returns the progress of the rollout", func(t *testing.T) {
+			toolResult, err := c.callTool("rollout_status", map[string]interface{}{"namespace": "default", "kind": "Deployment", "name": "web"})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			var result map[string]interface{}
+			_ = yaml.Unmarshal([]byte(toolResult.Content[0].(mcp.TextContent).Text), &result)
+			if result["done"] != false || result["message"] != `Waiting for deployment "web" rollout to finish: 2 out of 3 new replicas have been updated...` {
+				t.Fatalf("unexpected result %v", result)
+			}
+			conditions := result["conditions"].([]interface{})
+			if len(conditions) != 1 || conditions[0].(map[string]interface{})["reason"] != "ReplicaSetUpdated" {
+				t.Fatalf("unexpected conditions %v", conditions)
+			}
+		})
+		t.Run("rollout_status with wait waits until the rollout completes", func(t *testing.T) {
+			toolResult, err := c.callTool("rollout_status", map[string]interface{}{"namespace": "default", "kind": "Deployment", "name": "web", "wait": true, "timeout": 30})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			var result map[string]interface{}
+			_ = yaml.Unmarshal([]byte(toolResult.Content[0].(mcp.TextContent).Text), &result)
+			if result["done"] != true || result["message"] != `deployment "web" successfully rolled out` {
+				t.Fatalf("unexpected result %v", result)
+			}
+		})
+		t.Run("rollout_status with exceeded progress deadline returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("rollout_status", map[string]interface{}{"namespace": "default", "kind": "Deployment", "name": "stuck"})
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != `failed to get rollout status of Deployment stuck: deployment "stuck" exceeded its progress deadline` {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+		t.Run("rollout_status with OnDelete DaemonSet returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("rollout_status", map[string]interface{}{"namespace": "default", "kind": "DaemonSet", "name": "agent"})
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != "failed to get rollout status of DaemonSet agent: rollout status is only available for RollingUpdate strategy type" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+		t.Run("rollout_status with unsupported kind returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("rollout_status", map[string]interface{}{"namespace": "default", "kind": "Job", "name": "web"})
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != "failed to get rollout status of Job web: unsupported workload kind Job, supported kinds are: Deployment, StatefulSet, DaemonSet" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+	})
+}
+
+func TestRolloutDeployment(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		deployment := &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "web-uid", Annotations: map[string]string{"deployment.kubernetes.io/revision": "2"}},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+					Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: "nginx:1.27"}}},
+				},
+			},
+		}
+		replicaSet := func(name, revision, image string, controlled bool) appsv1.ReplicaSet {
+			ownerReference := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: ptr.To(controlled)}
+			return appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, OwnerReferences: []metav1.OwnerReference{ownerReference},
+					Annotations: map[string]string{"deployment.kubernetes.io/revision": revision, "kubernetes.io/change-cause": "image " + image}},
+				Spec: appsv1.ReplicaSetSpec{Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web", "pod-template-hash": name}},
+					Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: image}}},
+				}},
+			}
+		}
+		var mutex sync.Mutex
+		var replicaSetsLabelSelector string
+		var patchType string
+		var patch map[string]interface{}
+		var patches []map[string]interface{}
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			switch req.URL.Path {
+			case "/apis/apps/v1/namespaces/default/deployments/web":
+				if req.Method == http.MethodPatch {
+					patchType = req.Header.Get("Content-Type")
+					body, _ := io.ReadAll(req.Body)
+					patch, patches = nil, nil
+					_ = json.Unmarshal(body, &patch)
+					_ = json.Unmarshal(body, &patches)
+				}
+				writeObject(w, deployment)
+			case "/apis/apps/v1/namespaces/default/replicasets":
+				replicaSetsLabelSelector = req.URL.Query().Get("labelSelector")
+				writeObject(w, &appsv1.ReplicaSetList{
+					TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSetList"},
+					Items: []appsv1.ReplicaSet{
+						replicaSet("web-2", "2", "nginx:1.27", true),
+						replicaSet("web-1", "1", "nginx:1.26", true),
+						replicaSet("other", "3", "nginx:1.28", false),
+					},
+				})
+			}
+		}))
+		t.Run("rollout_history returns the ReplicaSet revisions sorted by revision", func(t *testing.T) {
+			toolResult, err := c.callTool("rollout_history", map[string]interface{}{"namespace": "default", "kind": "Deployment", "name": "web"})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			text := toolResult.Content[0].(mcp.TextContent).Text
+			if !strings.HasPrefix(text, "The following revisions (YAML format) were found for Deployment web:\n") {
+				t.Fatalf("unexpected result %v", text)
+			}
+			if replicaSetsLabelSelector != "app=web" {
+				t.Fatalf("unexpected label selector %s", replicaSetsLabelSelector)
+			}
+			var result []map[string]interface{}
+			_ = yaml.Unmarshal([]byte(text[strings.Index(text, "\n")+1:]), &result)
+			if len(result) != 2 ||
+				result[0]["revision"] != float64(1) || result[0]["name"] != "web-1" || result[0]["changeCause"] != "image nginx:1.26" ||
+				result[1]["revision"] != float64(2) || result[1]["images"].([]interface{})[0] != "nginx:1.27" {
+				t.Fatalf("unexpected result %v", result)
+			}
+		})
+		t.Run("rollout_restart patches the restartedAt annotation of the Pod template", func(t *testing.T) {
+			toolResult, err := c.callTool("rollout_restart", map[string]interface{}{"namespace": "default", "kind": "Deployment", "name": "web"})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			if toolResult.Content[0].(mcp.TextContent).Text != "Deployment web in namespace default restarted (use rollout_status to follow the rollout)" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+			if patchType != "application/strategic-merge-patch+json" {
+				t.Fatalf("unexpected patch type %s", patchType)
+			}
+			restartedAt, _, _ := unstructured.NestedString(patch, "spec", "template", "metadata", "annotations", "kubectl.kubernetes.io/restartedAt")
+			if _, err = time.Parse(time.RFC3339, restartedAt); err != nil {
+				t.Fatalf("unexpected restartedAt annotation %v", patch)
+			}
+		})
+		t.Run("rollout_undo rolls back to the previous revision", func(t *testing.T) {
+			toolResult, err := c.callTool("rollout_undo", map[string]interface{}{"namespace": "default", "kind": "Deployment", "name": "web"})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			if toolResult.Content[0].(mcp.TextContent).Text != "Deployment web in namespace default rolled back to revision 1" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+			if patchType != "application/json-patch+json" || len(patches) != 2 {
+				t.Fatalf("unexpected patch %s %v", patchType, patches)
+			}
+			template := patches[0]["value"].(map[string]interface{})
+			image, _, _ := unstructured.NestedSlice(template, "spec", "containers")
+			if patches[0]["path"] != "/spec/template" || image[0].(map[string]interface{})["image"] != "nginx:1.26" {
+				t.Fatalf("unexpected template patch %v", patches[0])
+			}
+			if _, ok := template["metadata"].(map[string]interface{})["labels"].(map[string]interface{})["pod-template-hash"]; ok {
+				t.Fatalf("unexpected pod-template-hash label %v", template)
+			}
+			annotations := patches[1]["value"].(map[string]interface{})
+			if annotations["deployment.kubernetes.io/revision"] != "2" || annotations["kubernetes.io/change-cause"] != "image nginx:1.26" {
+				t.Fatalf("unexpected annotations patch %v", patches[1])
+			}
+		})
+		t.Run("rollout_undo to the current revision is skipped", func(t *testing.T) {
+			patchType = ""
+			toolResult, err := c.callTool("rollout_undo", map[string]interface{}{"namespace": "default", "kind": "Deployment", "name": "web", "toRevision": 2})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			if toolResult.Content[0].(mcp.TextContent).Text != "Skipped rollback of Deployment web (current template already matches revision 2)" || patchType != "" {
+				t.Fatalf("unexpected result %v %s", toolResult.Content, patchType)
+			}
+		})
+		t.Run("rollout_undo to missing revision returns error", func(t *testing.T) {
+			toolResult, _ := c.callTool("rollout_undo", map[string]interface{}{"namespace": "default", "kind": "Deployment", "name": "web", "toRevision": 3})
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != "failed to roll back Deployment web: unable to find the specified revision 3 in the history of Deployment web" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+		t.Run("rollout_restart with paused Deployment returns error", func(t *testing.T) {
+			mutex.Lock()
+			deployment.Spec.Paused = true
+			mutex.Unlock()
+			toolResult, _ := c.callTool("rollout_restart", map[string]interface{}{"namespace": "default", "kind": "Deployment", "name": "web"})
+			if !toolResult.IsError || toolResult.Content[0].(mcp.TextContent).Text != "failed to restart Deployment web: can't restart paused deployment web (resume it first)" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+		})
+	})
+}
+
+func TestRolloutStatefulSet(t *testing.T) {
+	testCase(t, func(c *mcpContext) {
+		mockServer := NewMockServer()
+		defer mockServer.Close()
+		c.withKubeConfig(mockServer.config)
+		controllerRevision := func(name string, revision int64, image string) appsv1.ControllerRevision {
+			data, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": map[string]interface{}{
+				"$patch": "replace",
+				"spec":   map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "db", "image": image}}},
+			}}})
+			return appsv1.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db", UID: "db-uid", Controller: ptr.To(true)},
+				}},
+				Data:     runtime.RawExtension{Raw: data},
+				Revision: revision,
+			}
+		}
+		var patch map[string]interface{}
+		mockServer.Handle(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/apis/apps/v1/namespaces/default/statefulsets/db":
+				if req.Method == http.MethodPatch {
+					body, _ := io.ReadAll(req.Body)
+					_ = json.Unmarshal(body, &patch)
+				}
+				writeObject(w, &appsv1.StatefulSet{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db", UID: "db-uid"},
+					Spec:       appsv1.StatefulSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+				})
+			case "/apis/apps/v1/namespaces/default/controllerrevisions":
+				writeObject(w, &appsv1.ControllerRevisionList{
+					TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ControllerRevisionList"},
+					Items:    []appsv1.ControllerRevision{controllerRevision("db-b", 2, "postgres:17"), controllerRevision("db-a", 1, "postgres:16")},
+				})
+			}
+		}))
+		t.Run("rollout_history returns the ControllerRevision revisions", func(t *testing.T) {
+			toolResult, err := c.callTool("rollout_history", map[string]interface{}{"namespace": "default", "kind": "StatefulSet", "name": "db"})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			text := toolResult.Content[0].(mcp.TextContent).Text
+			var result []map[string]interface{}
+			_ = yaml.Unmarshal([]byte(text[strings.Index(text, "\n")+1:]), &result)
+			if len(result) != 2 || result[0]["name"] != "db-a" || result[0]["images"].([]interface{})[0] != "postgres:16" || result[1]["revision"] != float64(2) {
+				t.Fatalf("unexpected result %v", result)
+			}
+		})
+		t.Run("rollout_undo applies the ControllerRevision patch", func(t *testing.T) {
+			toolResult, err := c.callTool("rollout_undo", map[string]interface{}{"namespace": "default", "kind": "StatefulSet", "name": "db", "toRevision": 1})
+			if err != nil || toolResult.IsError {
+				t.Fatalf("call tool failed %v %v", err, toolResult.Content)
+			}
+			if toolResult.Content[0].(mcp.TextContent).Text != "StatefulSet db in namespace default rolled back to revision 1" {
+				t.Fatalf("unexpected result %v", toolResult.Content)
+			}
+			containers, _, _ := unstructured.NestedSlice(patch, "spec", "template", "spec", "containers")
+			if len(containers) != 1 || containers[0].(map[string]interface{})["image"] != "postgres:16" {
+				t.Fatalf("unexpected patch %v", patch)
+			}
+		})
+	})
+}
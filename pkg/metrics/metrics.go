@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const namespace = "kubernetes_mcp_server"
+
+var (
+	// Registry holds the server metrics (Go runtime and process metrics included)
+	Registry = prometheus.NewRegistry()
+
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Number of MCP tool calls partitioned by tool and result (success or error).",
+	}, []string{"tool", "result"})
+	toolCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Latency of the MCP tool calls in seconds partitioned by tool.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"tool"})
+	toolErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_errors_total",
+		Help:      "Number of failed MCP tool calls partitioned by tool and reason (Kubernetes status reason or server policy).",
+	}, []string{"tool", "reason"})
+	kubernetesRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kubernetes_request_duration_seconds",
+		Help:      "Latency of the Kubernetes API server requests in seconds partitioned by verb and host.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"verb", "host"})
+	kubernetesRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kubernetes_requests_total",
+		Help:      "Number of Kubernetes API server requests partitioned by status code, method and host.",
+	}, []string{"code", "method", "host"})
+
+	registerOnce sync.Once
+)
+
+// Register registers the server metrics and the client-go request metrics, it's safe to call it multiple times
+func Register() {
+	registerOnce.Do(func() {
+		Registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+			toolCalls,
+			toolCallDuration,
+			toolErrors,
+			kubernetesRequestDuration,
+			kubernetesRequests,
+		)
+		clientmetrics.Register(clientmetrics.RegisterOpts{
+			RequestLatency: &latencyAdapter{kubernetesRequestDuration},
+			RequestResult:  &resultAdapter{kubernetesRequests},
+		})
+	})
+}
+
+// Handler returns the HTTP handler that exposes the metrics in the Prometheus format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveToolCall records the result and latency of a tool call, reason is empty if the call succeeded
+func ObserveToolCall(tool string, duration time.Duration, reason string) {
+	toolCallDuration.WithLabelValues(tool).Observe(duration.Seconds())
+	if reason == "" {
+		toolCalls.WithLabelValues(tool, "success").Inc()
+		return
+	}
+	toolCalls.WithLabelValues(tool, "error").Inc()
+	toolErrors.WithLabelValues(tool, reason).Inc()
+}
+
+// latencyAdapter implements the client-go LatencyMetric interface
+type latencyAdapter struct {
+	histogram *prometheus.HistogramVec
+}
+
+func (l *latencyAdapter) Observe(_ context.Context, verb string, u url.URL, latency time.Duration) {
+	l.histogram.WithLabelValues(verb, u.Host).Observe(latency.Seconds())
+}
+
+// resultAdapter implements the client-go ResultMetric interface
+type resultAdapter struct {
+	counter *prometheus.CounterVec
+}
+
+func (r *resultAdapter) Increment(_ context.Context, code, method, host string) {
+	r.counter.WithLabelValues(code, method, host).Inc()
+}
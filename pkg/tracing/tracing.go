@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/manusa/kubernetes-mcp-server/pkg/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"strings"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+
+	instrumentationName = "github.com/manusa/kubernetes-mcp-server"
+)
+
+type Options struct {
+	// Exporter is the span exporter: none, otlp, stdout, or file (defaults to the OTEL_TRACES_EXPORTER environment variable)
+	Exporter string
+	// Endpoint is the OTLP/HTTP endpoint URL (defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variables)
+	Endpoint string
+	// File is the path of the file where the file exporter appends the spans as JSON
+	File string
+}
+
+// Init sets up the global tracer provider with the configured exporter.
+// The returned function flushes the pending spans and releases the exporter, it must be called before the process exits.
+func Init(ctx context.Context, options Options) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	exporterName := options.Exporter
+	if exporterName == "" {
+		exporterName = os.Getenv("OTEL_TRACES_EXPORTER")
+	}
+	var exporter sdktrace.SpanExporter
+	var closeFile func() error
+	var err error
+	switch strings.ToLower(exporterName) {
+	case "", ExporterNone:
+		return noop, nil
+	case ExporterOTLP:
+		var otlpOptions []otlptracehttp.Option
+		if options.Endpoint != "" {
+			otlpOptions = append(otlpOptions, otlptracehttp.WithEndpointURL(options.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, otlpOptions...)
+	case ExporterStdout, "console":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		if options.File == "" {
+			return noop, errors.New("file tracing exporter requires a tracing file")
+		}
+		file, openErr := os.OpenFile(options.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if openErr != nil {
+			return noop, fmt.Errorf("failed to open tracing file: %w", openErr)
+		}
+		closeFile = file.Close
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	default:
+		return noop, fmt.Errorf("invalid tracing exporter %q, valid values are: %s, %s, %s, %s",
+			exporterName, ExporterNone, ExporterOTLP, ExporterStdout, ExporterFile)
+	}
+	if err != nil {
+		return noop, fmt.Errorf("failed to create tracing exporter: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(version.BinaryName), semconv.ServiceVersion(version.Version)),
+	)
+	if err != nil {
+		return noop, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+	// The sampler can be configured with the standard OTEL_TRACES_SAMPLER environment variables
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return func(ctx context.Context) error {
+		err := tracerProvider.Shutdown(ctx)
+		if closeFile != nil {
+			err = errors.Join(err, closeFile())
+		}
+		return err
+	}, nil
+}
+
+// Tracer returns the server tracer, spans are discarded unless Init configured an exporter
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName, trace.WithInstrumentationVersion(version.Version))
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitNone(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	shutdown, err := Init(context.Background(), Options{})
+	t.Run("Init without exporter succeeds", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err = shutdown(context.Background()); err != nil {
+			t.Fatalf("expected no error on shutdown, got %v", err)
+		}
+	})
+}
+
+func TestInitInvalidExporter(t *testing.T) {
+	_, err := Init(context.Background(), Options{Exporter: "jaeger"})
+	t.Run("Init with invalid exporter returns error", func(t *testing.T) {
+		if err == nil || !strings.Contains(err.Error(), "invalid tracing exporter \"jaeger\"") {
+			t.Fatalf("expected invalid exporter error, got %v", err)
+		}
+	})
+	_, err = Init(context.Background(), Options{Exporter: ExporterFile})
+	t.Run("Init with file exporter without file returns error", func(t *testing.T) {
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestInitFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Init(context.Background(), Options{Exporter: ExporterFile, File: file})
+	if err != nil {
+		t.Fatalf("failed to initialize tracing %v", err)
+	}
+	_, span := Tracer().Start(context.Background(), "test-span")
+	span.End()
+	if err = shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shutdown tracing %v", err)
+	}
+	traces, err := os.ReadFile(file)
+	t.Run("File exporter writes spans to file", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("failed to read traces file %v", err)
+		}
+		if !strings.Contains(string(traces), `"Name":"test-span"`) {
+			t.Fatalf("expected test-span in traces file, got %s", traces)
+		}
+	})
+	t.Run("File exporter includes service name", func(t *testing.T) {
+		if !strings.Contains(string(traces), "kubernetes-mcp-server") {
+			t.Fatalf("expected service name in traces file, got %s", traces)
+		}
+	})
+}